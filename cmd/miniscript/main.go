@@ -0,0 +1,168 @@
+// Comando miniscript: ejecuta, tokeniza, muestra el AST o revisa la sintaxis
+// de un archivo .ms.
+//
+// Uso:
+//
+//	miniscript run archivo.ms     ejecuta el programa
+//	miniscript tokens archivo.ms  imprime la lista de tokens
+//	miniscript ast archivo.ms     imprime el árbol sintáctico
+//	miniscript check archivo.ms   solo revisa errores léxicos y sintácticos
+//
+// Con -escapes antes del comando las cadenas aceptan secuencias '\' como
+// \n o \u00e9 (lexer.WithEscapes).
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/DAlfaroV/miniscript/internal/interpreter"
+	"github.com/DAlfaroV/miniscript/internal/lexer"
+	"github.com/DAlfaroV/miniscript/internal/parser"
+	"github.com/DAlfaroV/miniscript/internal/parser/ast"
+)
+
+// Códigos de salida del proceso.
+const (
+	exitOK           = 0
+	exitUsage        = 1 // argumentos inválidos o archivo ilegible
+	exitLexError     = 2
+	exitParseError   = 3
+	exitRuntimeError = 4
+)
+
+const usage = `uso: miniscript [-escapes] <comando> archivo.ms
+
+comandos:
+  run     ejecuta el programa
+  tokens  imprime la lista de tokens
+  ast     imprime el árbol sintáctico
+  check   solo revisa errores léxicos y sintácticos
+
+opciones:
+  -escapes  acepta secuencias '\' en las cadenas, como \n o \u00e9
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run ejecuta el comando indicado en args y devuelve el código de salida.
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("miniscript", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	escapes := flags.Bool("escapes", false, "")
+	if err := flags.Parse(args); err != nil || flags.NArg() != 2 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+	cmd, path := flags.Arg(0), flags.Arg(1)
+
+	var opts []lexer.Option
+	if *escapes {
+		opts = append(opts, lexer.WithEscapes())
+	}
+
+	var err error
+	switch cmd {
+	case "run":
+		err = runFile(path, stdout, opts...)
+	case "tokens":
+		err = dumpTokens(path, stdout, opts...)
+	case "ast":
+		err = dumpAST(path, stdout, opts...)
+	case "check":
+		err = checkFile(path, opts...)
+	default:
+		fmt.Fprintf(stderr, "comando desconocido: %s\n\n%s", cmd, usage)
+		return exitUsage
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitCode(err)
+	}
+	return exitOK
+}
+
+// exitCode asocia cada clase de error con su código de salida.
+func exitCode(err error) int {
+	var lexErr *lexer.LexError
+	var parseErr *parser.ParseError
+	var runtimeErr *interpreter.RuntimeError
+	switch {
+	case errors.As(err, &lexErr):
+		return exitLexError
+	case errors.As(err, &parseErr):
+		return exitParseError
+	case errors.As(err, &runtimeErr):
+		return exitRuntimeError
+	default:
+		return exitUsage
+	}
+}
+
+func scanFile(path string, opts ...lexer.Option) ([]lexer.Token, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return lexer.NewLexer(string(src), opts...).ScanTokens()
+}
+
+func parseFile(path string, opts ...lexer.Option) (*ast.Program, error) {
+	tokens, err := scanFile(path, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return parser.New(tokens).ParseProgram()
+}
+
+func runFile(path string, stdout io.Writer, opts ...lexer.Option) error {
+	prog, err := parseFile(path, opts...)
+	if err != nil {
+		return err
+	}
+	return interpreter.New(stdout).Run(prog)
+}
+
+// dumpTokens imprime la lista completa de tokens, incluidos los TOKEN_ILLEGAL,
+// y luego reporta los errores léxicos encontrados.
+func dumpTokens(path string, stdout io.Writer, opts ...lexer.Option) error {
+	tokens, lexErr := scanFile(path, append(opts, lexer.WithErrorRecovery())...)
+	if tokens == nil {
+		return lexErr
+	}
+	for _, tok := range tokens {
+		span := tok.Span
+		line := fmt.Sprintf("%d:%d-%d:%d\t%s\t%q",
+			span.Start.Line, span.Start.Column, span.End.Line, span.End.Column, tok.Type, tok.Lexeme)
+		if tok.Literal != nil {
+			line += fmt.Sprintf("\t%v", tok.Literal)
+		}
+		if _, err := fmt.Fprintln(stdout, line); err != nil {
+			return err
+		}
+	}
+	return lexErr
+}
+
+func dumpAST(path string, stdout io.Writer, opts ...lexer.Option) error {
+	prog, err := parseFile(path, opts...)
+	if err != nil {
+		return err
+	}
+	return ast.Fprint(stdout, prog)
+}
+
+// checkFile reporta todos los errores léxicos o, si no los hay, todos los sintácticos.
+func checkFile(path string, opts ...lexer.Option) error {
+	tokens, err := scanFile(path, append(opts, lexer.WithErrorRecovery())...)
+	if err != nil {
+		return err
+	}
+	_, err = parser.New(tokens).ParseProgram()
+	return err
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DAlfaroV/miniscript/internal/lexer"
+)
+
+// writeScript guarda src en un archivo .ms temporal y devuelve su ruta.
+func writeScript(t *testing.T, src string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "prog.ms")
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatalf("Error escribiendo %s: %v", path, err)
+	}
+	return path
+}
+
+func TestRunExitCodes(t *testing.T) {
+	ok := writeScript(t, "x = 1\nprint x + 1\n")
+	lexErr := writeScript(t, "x = $\ny = 2\n")
+	twoLexErrs := writeScript(t, "x = $\ny = $\n")
+	parseErr := writeScript(t, "x = (1\ny = )\n")
+	runtimeErr := writeScript(t, "print y\n")
+	missing := filepath.Join(t.TempDir(), "no-existe.ms")
+
+	cases := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"run ok", []string{"run", ok}, exitOK},
+		{"check ok", []string{"check", ok}, exitOK},
+		{"tokens ok", []string{"tokens", ok}, exitOK},
+		{"sin argumentos", nil, exitUsage},
+		{"comando desconocido", []string{"compile", ok}, exitUsage},
+		{"opción desconocida", []string{"-x", "run", ok}, exitUsage},
+		{"check con escapes inválidos", []string{"-escapes", "check", writeScript(t, "s = \"\\q\"\n")}, exitLexError},
+		{"run archivo inexistente", []string{"run", missing}, exitUsage},
+		{"check archivo inexistente", []string{"check", missing}, exitUsage},
+		{"tokens archivo inexistente", []string{"tokens", missing}, exitUsage},
+		{"run error léxico", []string{"run", lexErr}, exitLexError},
+		{"check errores léxicos", []string{"check", twoLexErrs}, exitLexError},
+		{"tokens errores léxicos", []string{"tokens", twoLexErrs}, exitLexError},
+		{"run error sintáctico", []string{"run", parseErr}, exitParseError},
+		{"check errores sintácticos", []string{"check", parseErr}, exitParseError},
+		{"tokens no parsea", []string{"tokens", parseErr}, exitOK},
+		{"run error de ejecución", []string{"run", runtimeErr}, exitRuntimeError},
+		{"check no ejecuta", []string{"check", runtimeErr}, exitOK},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if got := run(tc.args, &stdout, &stderr); got != tc.want {
+				t.Errorf("código de salida = %d, se esperaba %d (stderr: %q)", got, tc.want, stderr.String())
+			}
+			if tc.want != exitOK && stderr.Len() == 0 {
+				t.Error("se esperaba un mensaje en stderr")
+			}
+		})
+	}
+}
+
+func TestRunOutput(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	path := writeScript(t, "x = 1\nprint x + 1\n")
+	if code := run([]string{"run", path}, &stdout, &stderr); code != exitOK {
+		t.Fatalf("código de salida = %d (stderr: %q)", code, stderr.String())
+	}
+	if got := stdout.String(); got != "2\n" {
+		t.Errorf("salida = %q, se esperaba %q", got, "2\n")
+	}
+}
+
+func TestRunEscapesFlag(t *testing.T) {
+	path := writeScript(t, "print \"a\\nb\"\n")
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"run", path}, "a\\nb\n"},
+		{[]string{"-escapes", "run", path}, "a\nb\n"},
+	} {
+		var stdout, stderr bytes.Buffer
+		if code := run(tc.args, &stdout, &stderr); code != exitOK {
+			t.Fatalf("%v: código de salida = %d (stderr: %q)", tc.args, code, stderr.String())
+		}
+		if got := stdout.String(); got != tc.want {
+			t.Errorf("%v: salida = %q, se esperaba %q", tc.args, got, tc.want)
+		}
+	}
+}
+
+// TestCheckReportsEveryLexError verifica que en modo de recuperación el
+// ErrorList llega completo a stderr y sigue asociado al código de error léxico.
+func TestCheckReportsEveryLexError(t *testing.T) {
+	path := writeScript(t, "x = $\ny = $\n")
+	err := checkFile(path)
+	var list lexer.ErrorList
+	if !errors.As(err, &list) || len(list) != 2 {
+		t.Fatalf("se esperaba un ErrorList con 2 errores, se obtuvo %v", err)
+	}
+	if code := exitCode(err); code != exitLexError {
+		t.Errorf("exitCode = %d, se esperaba %d", code, exitLexError)
+	}
+
+	var stdout, stderr bytes.Buffer
+	run([]string{"check", path}, &stdout, &stderr)
+	if n := strings.Count(stderr.String(), "$"); n != 2 {
+		t.Errorf("stderr = %q, se esperaban los 2 errores", stderr.String())
+	}
+}
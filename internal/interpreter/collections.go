@@ -0,0 +1,234 @@
+package interpreter
+
+import (
+	"fmt"
+	"math"
+)
+
+// indexValue devuelve obj[idx] para listas, cadenas y mapas. En listas y
+// cadenas los índices negativos cuentan desde el final: -1 es el último
+// elemento; en mapas la búsqueda sigue la cadena de __isa.
+func indexValue(obj, idx Value) (Value, error) {
+	switch o := obj.(type) {
+	case *Map:
+		v, _, ok := lookupMember(o, idx)
+		if !ok {
+			return nil, &RuntimeError{Message: fmt.Sprintf("Clave no encontrada: %s", repr(idx))}
+		}
+		return v, nil
+	case *List:
+		n, err := elementIndex(idx, len(o.Elements))
+		if err != nil {
+			return nil, err
+		}
+		return o.Elements[n], nil
+	case string:
+		chars := []rune(o)
+		n, err := elementIndex(idx, len(chars))
+		if err != nil {
+			return nil, err
+		}
+		return string(chars[n]), nil
+	default:
+		return nil, &RuntimeError{Message: fmt.Sprintf("No se puede indexar un valor de tipo %s", typeName(obj))}
+	}
+}
+
+// setIndex asigna obj[idx] = v. Las cadenas son inmutables.
+func setIndex(obj, idx, v Value) error {
+	if m, ok := obj.(*Map); ok {
+		m.Set(idx, v)
+		return nil
+	}
+	list, ok := obj.(*List)
+	if !ok {
+		return &RuntimeError{Message: fmt.Sprintf("No se puede asignar por índice en un valor de tipo %s", typeName(obj))}
+	}
+	n, err := elementIndex(idx, len(list.Elements))
+	if err != nil {
+		return err
+	}
+	list.Elements[n] = v
+	return nil
+}
+
+// memberValue devuelve obj.name, que equivale a obj["name"] en un mapa.
+// owner es el mapa de la cadena de __isa donde se encontró el miembro.
+func memberValue(obj Value, name string) (v Value, owner *Map, err error) {
+	m, ok := obj.(*Map)
+	if !ok {
+		return nil, nil, &RuntimeError{Message: fmt.Sprintf("No se puede acceder a '.%s' en un valor de tipo %s", name, typeName(obj))}
+	}
+	v, owner, found := lookupMember(m, name)
+	if !found {
+		return nil, nil, &RuntimeError{Message: fmt.Sprintf("Clave no encontrada: %s", repr(name))}
+	}
+	return v, owner, nil
+}
+
+// setMember asigna obj.name = v.
+func setMember(obj Value, name string, v Value) error {
+	m, ok := obj.(*Map)
+	if !ok {
+		return &RuntimeError{Message: fmt.Sprintf("No se puede asignar '.%s' en un valor de tipo %s", name, typeName(obj))}
+	}
+	m.Set(name, v)
+	return nil
+}
+
+// sliceValue devuelve obj[start:end] como una lista o cadena nueva. Los
+// extremos nil toman el inicio o el final; los que se salen del rango se recortan.
+func sliceValue(obj, start, end Value) (Value, error) {
+	switch o := obj.(type) {
+	case *List:
+		from, to, err := sliceBounds(start, end, len(o.Elements))
+		if err != nil {
+			return nil, err
+		}
+		elems := make([]Value, to-from)
+		copy(elems, o.Elements[from:to])
+		return &List{Elements: elems}, nil
+	case string:
+		chars := []rune(o)
+		from, to, err := sliceBounds(start, end, len(chars))
+		if err != nil {
+			return nil, err
+		}
+		return string(chars[from:to]), nil
+	default:
+		return nil, &RuntimeError{Message: fmt.Sprintf("No se puede cortar un valor de tipo %s", typeName(obj))}
+	}
+}
+
+// elementIndex convierte idx en una posición válida dentro de una secuencia de largo length.
+func elementIndex(idx Value, length int) (int, error) {
+	f, ok := idx.(float64)
+	if !ok {
+		return 0, &RuntimeError{Message: fmt.Sprintf("El índice debe ser un número, se obtuvo %s", typeName(idx))}
+	}
+	n := int(math.Floor(f))
+	if n < 0 {
+		n += length
+	}
+	if n < 0 || n >= length {
+		return 0, &RuntimeError{Message: fmt.Sprintf("Índice fuera de rango: %s (largo %d)", formatNumber(f), length)}
+	}
+	return n, nil
+}
+
+// sliceBounds resuelve los extremos de un corte sobre una secuencia de largo length.
+func sliceBounds(start, end Value, length int) (int, int, error) {
+	from, err := sliceBound(start, 0, length)
+	if err != nil {
+		return 0, 0, err
+	}
+	to, err := sliceBound(end, length, length)
+	if err != nil {
+		return 0, 0, err
+	}
+	if to < from {
+		to = from
+	}
+	return from, to, nil
+}
+
+func sliceBound(v Value, def, length int) (int, error) {
+	if v == nil {
+		return def, nil
+	}
+	f, ok := v.(float64)
+	if !ok {
+		return 0, &RuntimeError{Message: fmt.Sprintf("Los extremos del corte deben ser números, se obtuvo %s", typeName(v))}
+	}
+	n := int(math.Floor(f))
+	if n < 0 {
+		n += length
+	}
+	return min(max(n, 0), length), nil
+}
+
+// listOp aplica los operadores de listas: '+' concatena y '*' replica.
+// ok es false si el operador no aplica a estos operandos.
+func listOp(op string, left, right Value) (result Value, ok bool, err error) {
+	l, isList := left.(*List)
+	if !isList {
+		return nil, false, nil
+	}
+	switch op {
+	case "+":
+		r, isList := right.(*List)
+		if !isList {
+			return nil, false, nil
+		}
+		elems := make([]Value, 0, len(l.Elements)+len(r.Elements))
+		elems = append(elems, l.Elements...)
+		elems = append(elems, r.Elements...)
+		return &List{Elements: elems}, true, nil
+	case "*":
+		times, isNum := right.(float64)
+		if !isNum {
+			return nil, false, nil
+		}
+		// La cantidad de copias se acota antes de convertirla a int, que con
+		// NaN o valores enormes depende de la plataforma; incluso una lista
+		// vacía no puede replicarse más de maxListLength veces.
+		if math.IsNaN(times) || math.IsInf(times, 0) || times > maxListLength {
+			return nil, true, &RuntimeError{Message: fmt.Sprintf("No se puede replicar una lista %s veces", formatNumber(times))}
+		}
+		copies := max(int(times), 0)
+		n := len(l.Elements) * copies
+		if n > maxListLength {
+			return nil, true, &RuntimeError{Message: fmt.Sprintf("La lista replicada tendría más de %d elementos", maxListLength)}
+		}
+		elems := make([]Value, 0, n)
+		for range copies {
+			elems = append(elems, l.Elements...)
+		}
+		return &List{Elements: elems}, true, nil
+	}
+	return nil, false, nil
+}
+
+// iterate implementa el protocolo de iteración de 'for x in v': devuelve una
+// función next que entrega el siguiente elemento y false al agotarse. Las
+// listas se recorren por índice, de modo que ven los elementos agregados
+// durante el ciclo; las cadenas entregan sus caracteres y los mapas un mapa
+// {"key": k, "value": v} por entrada, en orden de inserción.
+func iterate(v Value) (next func() (Value, bool), err error) {
+	idx := 0
+	switch val := v.(type) {
+	case *List:
+		return func() (Value, bool) {
+			if idx >= len(val.Elements) {
+				return nil, false
+			}
+			idx++
+			return val.Elements[idx-1], true
+		}, nil
+	case string:
+		chars := []rune(val)
+		return func() (Value, bool) {
+			if idx >= len(chars) {
+				return nil, false
+			}
+			idx++
+			return string(chars[idx-1]), true
+		}, nil
+	case *Map:
+		keys := val.Keys()
+		return func() (Value, bool) {
+			if idx >= len(keys) {
+				return nil, false
+			}
+			key := keys[idx]
+			idx++
+			entry := NewMap()
+			entry.Set("key", key)
+			value, _ := val.Get(key)
+			entry.Set("value", value)
+			return entry, true
+		}, nil
+	default:
+		return nil, &RuntimeError{Message: fmt.Sprintf("No se puede iterar sobre un valor de tipo %s", typeName(v))}
+	}
+}
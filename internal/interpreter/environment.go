@@ -0,0 +1,37 @@
+package interpreter
+
+// Environment guarda las variables de un ámbito y enlaza con el ámbito exterior.
+// Las variables viven en un Map para que 'locals', 'outer' y 'globals' puedan
+// exponerlas a los scripts como mapas comunes.
+type Environment struct {
+	vars  *Map
+	outer *Environment
+}
+
+// NewEnvironment crea un ámbito vacío cuyo padre es outer (nil para el global).
+func NewEnvironment(outer *Environment) *Environment {
+	return &Environment{
+		vars:  NewMap(),
+		outer: outer,
+	}
+}
+
+// Get busca name en este ámbito y, si no existe, en los exteriores.
+func (e *Environment) Get(name string) (Value, bool) {
+	for env := e; env != nil; env = env.outer {
+		if v, ok := env.vars.Get(name); ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// Set asigna name en este ámbito; como en MiniScript, la asignación siempre es local.
+func (e *Environment) Set(name string, v Value) {
+	e.vars.Set(name, v)
+}
+
+// Vars devuelve el mapa con las variables propias de este ámbito.
+func (e *Environment) Vars() *Map {
+	return e.vars
+}
@@ -0,0 +1,33 @@
+package interpreter
+
+import (
+	"fmt"
+
+	"github.com/DAlfaroV/miniscript/internal/lexer"
+	"github.com/DAlfaroV/miniscript/internal/parser/ast"
+)
+
+type RuntimeError struct {
+	Message string
+	Line    int
+	Column  int
+	Span    lexer.Span // Rango del nodo que provocó el error
+}
+
+func (e *RuntimeError) Error() string {
+	return fmt.Sprintf("[RuntimeError Line:%d Col:%d] %s", e.Line, e.Column, e.Message)
+}
+
+// withPosition ubica err en node si es un RuntimeError que aún no tiene posición.
+// Como se aplica al salir de cada nodo, el error queda en el nodo más interno.
+func withPosition(err error, node ast.Node) error {
+	rtErr, ok := err.(*RuntimeError)
+	if !ok || rtErr.Line != 0 {
+		return err
+	}
+	span := node.NodeSpan()
+	rtErr.Line = span.Start.Line
+	rtErr.Column = span.Start.Column
+	rtErr.Span = span
+	return err
+}
@@ -0,0 +1,741 @@
+package interpreter
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/DAlfaroV/miniscript/internal/parser/ast"
+)
+
+// Interpreter recorre el AST de MiniScript y ejecuta cada sentencia.
+type Interpreter struct {
+	globals *Environment // Ámbito global del programa
+	env     *Environment // Ámbito donde se ejecuta la sentencia actual
+	out     io.Writer    // Destino de la salida de print
+	depth   int          // Llamadas a funciones en curso
+}
+
+// maxCallDepth limita el anidamiento de llamadas para que una recursión sin
+// fin termine con un RuntimeError en lugar de desbordar la pila de Go.
+const maxCallDepth = 10000
+
+// breakSignal, continueSignal y returnSignal viajan como errores para
+// desenrollar la ejecución hasta el ciclo o la llamada que los atiende.
+// label es la etiqueta del ciclo destino, o "" para el más interno.
+type breakSignal struct {
+	label string
+}
+
+func (s breakSignal) Error() string { return jumpMessage("break", s.label) }
+
+type continueSignal struct {
+	label string
+}
+
+func (s continueSignal) Error() string { return jumpMessage("continue", s.label) }
+
+// jumpMessage describe un 'break' o 'continue' que no encontró su ciclo.
+func jumpMessage(keyword, label string) string {
+	if label == "" {
+		return fmt.Sprintf("'%s' fuera de un ciclo", keyword)
+	}
+	return fmt.Sprintf("'%s %s': no hay un ciclo con la etiqueta '%s' que lo contenga", keyword, label, label)
+}
+
+type returnSignal struct {
+	value Value
+}
+
+func (returnSignal) Error() string { return "'return' fuera de una función" }
+
+// New crea un intérprete que escribe la salida de print en out.
+func New(out io.Writer) *Interpreter {
+	globals := NewEnvironment(nil)
+	return &Interpreter{
+		globals: globals,
+		env:     globals,
+		out:     out,
+	}
+}
+
+// Run ejecuta todas las sentencias del programa en el ámbito global.
+func (i *Interpreter) Run(prog *ast.Program) error {
+	err := i.execBlock(prog.Statements)
+	switch err.(type) {
+	case returnSignal, breakSignal, continueSignal:
+		// ParseProgram ya rechaza con Check un salto fuera de su función o
+		// ciclo; solo llega hasta aquí en un AST que no pasó por Check.
+		return &RuntimeError{Message: err.Error()}
+	}
+	return err
+}
+
+// Globals devuelve el ámbito global, útil para inspeccionar variables tras Run.
+func (i *Interpreter) Globals() *Environment {
+	return i.globals
+}
+
+func (i *Interpreter) execBlock(stmts []ast.Statement) error {
+	for _, stmt := range stmts {
+		if err := i.exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (i *Interpreter) exec(stmt ast.Statement) error {
+	return withPosition(i.execStmt(stmt), stmt)
+}
+
+func (i *Interpreter) execStmt(stmt ast.Statement) error {
+	switch s := stmt.(type) {
+	case *ast.ExpressionStmt:
+		_, err := i.eval(s.Expr)
+		return err
+	case *ast.PrintStmt:
+		return i.execPrint(s)
+	case *ast.AssignmentStmt:
+		v, err := i.assignedValue(s.Operator, s.Value, func() (Value, error) {
+			return i.lookup(s.Name)
+		})
+		if err != nil {
+			return err
+		}
+		i.env.Set(s.Name, v)
+		return nil
+	case *ast.IndexAssignStmt:
+		return i.execIndexAssign(s)
+	case *ast.MemberAssignStmt:
+		obj, err := i.eval(s.Object)
+		if err != nil {
+			return err
+		}
+		v, err := i.assignedValue(s.Operator, s.Value, func() (Value, error) {
+			v, _, err := memberValue(obj, s.Name)
+			return v, err
+		})
+		if err != nil {
+			return err
+		}
+		return setMember(obj, s.Name, v)
+	case *ast.IfStmt:
+		return i.execIf(s)
+	case *ast.WhileStmt:
+		return i.execWhile(s)
+	case *ast.ForStmt:
+		return i.execFor(s)
+	case *ast.ForInStmt:
+		return i.execForIn(s)
+	case *ast.FunctionStmt:
+		i.env.Set(s.Name, &Function{Name: s.Name, Parameters: s.Parameters, Body: s.Body, Closure: i.env})
+		return nil
+	case *ast.ReturnStmt:
+		var v Value
+		if s.Value != nil {
+			var err error
+			if v, err = i.eval(s.Value); err != nil {
+				return err
+			}
+		}
+		return returnSignal{value: v}
+	case *ast.BreakStmt:
+		return breakSignal{label: s.Label}
+	case *ast.ContinueStmt:
+		return continueSignal{label: s.Label}
+	default:
+		return &RuntimeError{Message: fmt.Sprintf("Sentencia no soportada: %s", stmt.NodeType())}
+	}
+}
+
+func (i *Interpreter) execPrint(s *ast.PrintStmt) error {
+	v, err := i.eval(s.Value)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(i.out, stringify(v))
+	return err
+}
+
+func (i *Interpreter) execIndexAssign(s *ast.IndexAssignStmt) error {
+	obj, err := i.eval(s.Object)
+	if err != nil {
+		return err
+	}
+	idx, err := i.eval(s.Index)
+	if err != nil {
+		return err
+	}
+	v, err := i.assignedValue(s.Operator, s.Value, func() (Value, error) {
+		return indexValue(obj, idx)
+	})
+	if err != nil {
+		return err
+	}
+	return setIndex(obj, idx, v)
+}
+
+// assignedValue calcula el valor a guardar en una asignación. En una
+// asignación compuesta ('x += v') lee primero el valor actual con current,
+// de modo que el destino se evalúa una sola vez.
+func (i *Interpreter) assignedValue(op string, valueExpr ast.Expression, current func() (Value, error)) (Value, error) {
+	if op == "" || op == "=" {
+		return i.eval(valueExpr)
+	}
+	cur, err := current()
+	if err != nil {
+		return nil, err
+	}
+	v, err := i.eval(valueExpr)
+	if err != nil {
+		return nil, err
+	}
+	return binaryOp(strings.TrimSuffix(op, "="), cur, v)
+}
+
+func (i *Interpreter) execIf(s *ast.IfStmt) error {
+	cond, err := i.eval(s.Condition)
+	if err != nil {
+		return err
+	}
+	if isTruthy(cond) {
+		return i.execBlock(s.ThenBlock)
+	}
+	for idx, elifCond := range s.ElseIfConds {
+		cond, err := i.eval(elifCond)
+		if err != nil {
+			return err
+		}
+		if isTruthy(cond) {
+			return i.execBlock(s.ElseIfBods[idx])
+		}
+	}
+	return i.execBlock(s.ElseBlock)
+}
+
+func (i *Interpreter) execWhile(s *ast.WhileStmt) error {
+	for {
+		cond, err := i.eval(s.Condition)
+		if err != nil {
+			return err
+		}
+		if !isTruthy(cond) {
+			return nil
+		}
+		if stop, err := loopControl(i.execBlock(s.Body), s.Label); stop || err != nil {
+			return err
+		}
+	}
+}
+
+// execFor ejecuta el for numérico. La cantidad de vueltas se calcula antes
+// de empezar con stepCount y la variable toma start + k*step, así un paso
+// decimal no acumula errores de redondeo; reasignarla en el cuerpo no altera
+// el recorrido. Sin 'step' se avanza de a 1.
+func (i *Interpreter) execFor(s *ast.ForStmt) error {
+	start, err := i.evalNumber(s.StartExpr, "el inicio del for")
+	if err != nil {
+		return err
+	}
+	end, err := i.evalNumber(s.EndExpr, "el final del for")
+	if err != nil {
+		return err
+	}
+	step := 1.0
+	if s.StepExpr != nil {
+		if step, err = i.evalNumber(s.StepExpr, "el paso del for"); err != nil {
+			return err
+		}
+		if step == 0 {
+			return withPosition(&RuntimeError{Message: "El paso del for no puede ser 0"}, s.StepExpr)
+		}
+	}
+	count := stepCount(start, end, step)
+	for k := 0.0; k < count; k++ {
+		i.env.Set(s.VarName, start+k*step)
+		if stop, err := loopControl(i.execBlock(s.Body), s.Label); stop || err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// execForIn asigna a VarName cada elemento que entrega iterate sobre el
+// valor de Iterable.
+func (i *Interpreter) execForIn(s *ast.ForInStmt) error {
+	v, err := i.eval(s.Iterable)
+	if err != nil {
+		return err
+	}
+	next, err := iterate(v)
+	if err != nil {
+		return withPosition(err, s.Iterable)
+	}
+	for {
+		item, ok := next()
+		if !ok {
+			return nil
+		}
+		i.env.Set(s.VarName, item)
+		if stop, err := loopControl(i.execBlock(s.Body), s.Label); stop || err != nil {
+			return err
+		}
+	}
+}
+
+// loopControl interpreta el resultado de ejecutar el cuerpo del ciclo con
+// etiqueta label: stop indica que el ciclo debe terminar y err el error a
+// propagar. Un salto dirigido a otra etiqueta termina este ciclo y sigue
+// subiendo hasta el ciclo que la lleva.
+func loopControl(err error, label string) (stop bool, _ error) {
+	switch sig := err.(type) {
+	case nil:
+		return false, nil
+	case continueSignal:
+		if sig.label == "" || sig.label == label {
+			return false, nil
+		}
+		return true, err
+	case breakSignal:
+		if sig.label == "" || sig.label == label {
+			return true, nil
+		}
+		return true, err
+	default:
+		return true, err
+	}
+}
+
+func (i *Interpreter) evalNumber(expr ast.Expression, what string) (float64, error) {
+	v, err := i.eval(expr)
+	if err != nil {
+		return 0, err
+	}
+	n, ok := v.(float64)
+	if !ok {
+		return 0, &RuntimeError{Message: fmt.Sprintf("Se esperaba un número en %s, se obtuvo %s", what, typeName(v))}
+	}
+	return n, nil
+}
+
+func (i *Interpreter) eval(expr ast.Expression) (Value, error) {
+	v, err := i.evalExpr(expr)
+	if err != nil {
+		return nil, withPosition(err, expr)
+	}
+	return v, nil
+}
+
+func (i *Interpreter) evalExpr(expr ast.Expression) (Value, error) {
+	switch e := expr.(type) {
+	case *ast.LiteralExpr:
+		return e.Value, nil
+	case *ast.GroupingExpr:
+		return i.eval(e.Expression)
+	case *ast.InterpolatedStringExpr:
+		var sb strings.Builder
+		for _, part := range e.Parts {
+			v, err := i.eval(part)
+			if err != nil {
+				return nil, err
+			}
+			sb.WriteString(stringify(v))
+		}
+		return sb.String(), nil
+	case *ast.VariableExpr, *ast.MemberExpr:
+		// Como en MiniScript, nombrar una función la invoca sin argumentos;
+		// '@f' obtiene la función sin invocarla.
+		v, recv, err := i.evalRef(e)
+		if err != nil {
+			return nil, err
+		}
+		if fn, ok := v.(*Function); ok {
+			return i.callFunction(fn, nil, recv)
+		}
+		return v, nil
+	case *ast.AddressOfExpr:
+		v, _, err := i.evalRef(e.Expr)
+		return v, err
+	case *ast.FunctionExpr:
+		return &Function{Parameters: e.Parameters, Body: e.Body, Closure: i.env}, nil
+	case *ast.UnaryExpr:
+		return i.evalUnary(e)
+	case *ast.BinaryExpr:
+		return i.evalBinary(e)
+	case *ast.LogicalExpr:
+		return i.evalLogical(e)
+	case *ast.CallExpr:
+		return i.evalCall(e)
+	case *ast.ListExpr:
+		elems := make([]Value, 0, len(e.Elements))
+		for _, elemExpr := range e.Elements {
+			elem, err := i.eval(elemExpr)
+			if err != nil {
+				return nil, err
+			}
+			elems = append(elems, elem)
+		}
+		return &List{Elements: elems}, nil
+	case *ast.IndexExpr:
+		obj, err := i.eval(e.Object)
+		if err != nil {
+			return nil, err
+		}
+		idx, err := i.eval(e.Index)
+		if err != nil {
+			return nil, err
+		}
+		return indexValue(obj, idx)
+	case *ast.SliceExpr:
+		return i.evalSlice(e)
+	case *ast.MapExpr:
+		m := NewMap()
+		for idx, keyExpr := range e.Keys {
+			key, err := i.eval(keyExpr)
+			if err != nil {
+				return nil, err
+			}
+			v, err := i.eval(e.Values[idx])
+			if err != nil {
+				return nil, err
+			}
+			m.Set(key, v)
+		}
+		return m, nil
+	default:
+		return nil, &RuntimeError{Message: fmt.Sprintf("Expresión no soportada: %s", expr.NodeType())}
+	}
+}
+
+// evalRef evalúa expr sin invocar la función a la que pueda referirse.
+// Para 'obj.metodo' devuelve además el receptor con el que debe llamarse.
+func (i *Interpreter) evalRef(expr ast.Expression) (Value, receiver, error) {
+	switch e := expr.(type) {
+	case *ast.VariableExpr:
+		v, err := i.lookup(e.Name)
+		return v, receiver{}, withPosition(err, e)
+	case *ast.MemberExpr:
+		return i.evalMethod(e)
+	default:
+		v, err := i.eval(expr)
+		return v, receiver{}, err
+	}
+}
+
+// lookup resuelve una variable. 'locals', 'outer' y 'globals' devuelven los
+// mapas de variables del ámbito actual, del que encierra a la función y del
+// global. Las funciones intrínsecas se buscan al final, así que una variable
+// del script puede ocultarlas.
+func (i *Interpreter) lookup(name string) (Value, error) {
+	switch name {
+	case "locals":
+		return i.env.Vars(), nil
+	case "outer":
+		if i.env.outer == nil {
+			return i.globals.Vars(), nil
+		}
+		return i.env.outer.Vars(), nil
+	case "globals":
+		return i.globals.Vars(), nil
+	}
+	v, ok := i.env.Get(name)
+	if !ok {
+		if fn, ok := intrinsics[name]; ok {
+			return fn, nil
+		}
+		return nil, &RuntimeError{Message: fmt.Sprintf("Variable no definida: '%s'", name)}
+	}
+	return v, nil
+}
+
+func (i *Interpreter) evalSlice(e *ast.SliceExpr) (Value, error) {
+	obj, err := i.eval(e.Object)
+	if err != nil {
+		return nil, err
+	}
+	var start, end Value
+	if e.Start != nil {
+		if start, err = i.eval(e.Start); err != nil {
+			return nil, err
+		}
+	}
+	if e.End != nil {
+		if end, err = i.eval(e.End); err != nil {
+			return nil, err
+		}
+	}
+	return sliceValue(obj, start, end)
+}
+
+func (i *Interpreter) evalUnary(e *ast.UnaryExpr) (Value, error) {
+	right, err := i.eval(e.Right)
+	if err != nil {
+		return nil, err
+	}
+	switch e.Operator {
+	case "not":
+		return !isTruthy(right), nil
+	case "new":
+		return newInstance(right)
+	case "-":
+		n, ok := right.(float64)
+		if !ok {
+			return nil, &RuntimeError{Message: fmt.Sprintf("No se puede negar un valor de tipo %s", typeName(right))}
+		}
+		return -n, nil
+	default:
+		return nil, &RuntimeError{Message: fmt.Sprintf("Operador unario desconocido: '%s'", e.Operator)}
+	}
+}
+
+func (i *Interpreter) evalBinary(e *ast.BinaryExpr) (Value, error) {
+	left, err := i.eval(e.Left)
+	if err != nil {
+		return nil, err
+	}
+	right, err := i.eval(e.Right)
+	if err != nil {
+		return nil, err
+	}
+	return binaryOp(e.Operator, left, right)
+}
+
+// evalLogical evalúa 'and'/'or' en cortocircuito y devuelve un booleano
+// según la veracidad de MiniScript.
+func (i *Interpreter) evalLogical(e *ast.LogicalExpr) (Value, error) {
+	left, err := i.eval(e.Left)
+	if err != nil {
+		return nil, err
+	}
+	switch e.Operator {
+	case "and":
+		if !isTruthy(left) {
+			return false, nil
+		}
+	case "or":
+		if isTruthy(left) {
+			return true, nil
+		}
+	default:
+		return nil, &RuntimeError{Message: fmt.Sprintf("Operador lógico desconocido: '%s'", e.Operator)}
+	}
+	right, err := i.eval(e.Right)
+	if err != nil {
+		return nil, err
+	}
+	return isTruthy(right), nil
+}
+
+// binaryOp aplica un operador binario a dos valores ya evaluados.
+func binaryOp(op string, left, right Value) (Value, error) {
+	switch op {
+	case "==":
+		return valuesEqual(left, right), nil
+	case "!=":
+		return !valuesEqual(left, right), nil
+	case "isa":
+		return isaOp(left, right), nil
+	}
+
+	if result, ok, err := listOp(op, left, right); ok {
+		return result, err
+	}
+
+	// Si alguno de los operandos es cadena, '+' concatena.
+	if op == "+" {
+		_, ls := left.(string)
+		_, rs := right.(string)
+		if ls || rs {
+			return stringify(left) + stringify(right), nil
+		}
+	}
+
+	if ls, ok := left.(string); ok {
+		if rs, ok := right.(string); ok {
+			switch op {
+			case "<":
+				return ls < rs, nil
+			case "<=":
+				return ls <= rs, nil
+			case ">":
+				return ls > rs, nil
+			case ">=":
+				return ls >= rs, nil
+			}
+		}
+	}
+
+	l, lok := left.(float64)
+	r, rok := right.(float64)
+	if !lok || !rok {
+		return nil, &RuntimeError{Message: fmt.Sprintf("Operador '%s' no aplicable a %s y %s", op, typeName(left), typeName(right))}
+	}
+	switch op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/":
+		return l / r, nil
+	case "%":
+		return math.Mod(l, r), nil
+	case "^":
+		return math.Pow(l, r), nil
+	case "<":
+		return l < r, nil
+	case "<=":
+		return l <= r, nil
+	case ">":
+		return l > r, nil
+	case ">=":
+		return l >= r, nil
+	default:
+		return nil, &RuntimeError{Message: fmt.Sprintf("Operador binario desconocido: '%s'", op)}
+	}
+}
+
+func (i *Interpreter) evalCall(e *ast.CallExpr) (Value, error) {
+	callee, recv, err := i.evalRef(e.Callee)
+	if err != nil {
+		return nil, err
+	}
+	args := make([]Value, 0, len(e.Arguments))
+	for _, argExpr := range e.Arguments {
+		arg, err := i.eval(argExpr)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	fn, ok := callee.(*Function)
+	if !ok {
+		return nil, &RuntimeError{Message: fmt.Sprintf("No se puede llamar a un valor de tipo %s", typeName(callee))}
+	}
+	return i.callFunction(fn, args, recv)
+}
+
+// checkArity comprueba que fn pueda recibir n argumentos.
+func checkArity(fn *Function, n int) error {
+	required, maxArgs := 0, 0
+	for _, param := range fn.Parameters {
+		switch {
+		case param.Variadic:
+			maxArgs = -1
+		case param.Default == nil:
+			required++
+			maxArgs++
+		default:
+			maxArgs++
+		}
+	}
+	if n < required {
+		return &RuntimeError{Message: fmt.Sprintf("%s requiere al menos %d argumentos, se pasaron %d", fn.displayName(), required, n)}
+	}
+	if maxArgs >= 0 && n > maxArgs {
+		return &RuntimeError{Message: fmt.Sprintf("%s recibe como máximo %d argumentos, se pasaron %d", fn.displayName(), maxArgs, n)}
+	}
+	return nil
+}
+
+// bindArguments asigna los argumentos a los parámetros de fn en el ámbito
+// actual. Los valores por defecto se evalúan en cada llamada, así que pueden
+// usar los parámetros anteriores; el parámetro variádico recibe una lista.
+func (i *Interpreter) bindArguments(fn *Function, args []Value) error {
+	for idx, param := range fn.Parameters {
+		switch {
+		case param.Variadic:
+			var rest []Value
+			if idx < len(args) {
+				rest = append(rest, args[idx:]...)
+			}
+			i.env.Set(param.Name, &List{Elements: rest})
+		case idx < len(args):
+			i.env.Set(param.Name, args[idx])
+		default:
+			v, err := i.eval(param.Default)
+			if err != nil {
+				return err
+			}
+			i.env.Set(param.Name, v)
+		}
+	}
+	return nil
+}
+
+// receiver es el objeto sobre el que se invoca un método: self es el objeto
+// y super el prototipo del mapa donde se encontró el método.
+type receiver struct {
+	self  Value
+	super Value
+}
+
+// evalMethod resuelve 'obj.nombre' como método. En 'super.nombre' la búsqueda
+// empieza en super pero self sigue siendo el objeto actual.
+func (i *Interpreter) evalMethod(member *ast.MemberExpr) (Value, receiver, error) {
+	var obj, self Value
+	if v, ok := member.Object.(*ast.VariableExpr); ok && v.Name == "super" {
+		obj, _ = i.env.Get("super")
+		self, _ = i.env.Get("self")
+	} else {
+		var err error
+		if obj, err = i.eval(member.Object); err != nil {
+			return nil, receiver{}, err
+		}
+		self = obj
+	}
+	v, owner, err := memberValue(obj, member.Name)
+	if err != nil {
+		return nil, receiver{}, withPosition(err, member)
+	}
+	super, _ := owner.Get(isaKey)
+	return v, receiver{self: self, super: super}, nil
+}
+
+// callFunction ejecuta fn en un ámbito nuevo. Si recv tiene un objeto, el
+// cuerpo ve 'self' y 'super'.
+func (i *Interpreter) callFunction(fn *Function, args []Value, recv receiver) (Value, error) {
+	if err := checkArity(fn, len(args)); err != nil {
+		return nil, err
+	}
+	if i.depth >= maxCallDepth {
+		return nil, &RuntimeError{Message: fmt.Sprintf("Se superó la profundidad máxima de llamadas (%d)", maxCallDepth)}
+	}
+	i.depth++
+	defer func() { i.depth-- }()
+
+	env := NewEnvironment(fn.Closure)
+	if recv.self != nil {
+		env.Set("self", recv.self)
+		env.Set("super", recv.super)
+	}
+
+	prev := i.env
+	i.env = env
+	defer func() { i.env = prev }()
+
+	if err := i.bindArguments(fn, args); err != nil {
+		return nil, err
+	}
+	if fn.Native != nil {
+		bound := make([]Value, len(fn.Parameters))
+		for idx, param := range fn.Parameters {
+			bound[idx], _ = env.Vars().Get(param.Name)
+		}
+		return fn.Native(bound)
+	}
+	err := i.execBlock(fn.Body)
+	switch sig := err.(type) {
+	case nil:
+		return nil, nil
+	case returnSignal:
+		return sig.value, nil
+	case breakSignal, continueSignal:
+		return nil, &RuntimeError{Message: sig.Error()}
+	default:
+		return nil, err
+	}
+}
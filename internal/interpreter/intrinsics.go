@@ -0,0 +1,80 @@
+package interpreter
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/DAlfaroV/miniscript/internal/parser/ast"
+)
+
+// intrinsics son las funciones predefinidas, visibles desde cualquier ámbito.
+var intrinsics = map[string]*Function{}
+
+func init() {
+	defineIntrinsic("range", intrinsicRange, required("start"), optional("end", 0.0), optional("step", nil))
+}
+
+// defineIntrinsic registra una función intrínseca con los parámetros dados.
+func defineIntrinsic(name string, native func(args []Value) (Value, error), params ...*ast.Parameter) {
+	intrinsics[name] = &Function{Name: name, Parameters: params, Native: native}
+}
+
+// required declara un parámetro obligatorio de una intrínseca.
+func required(name string) *ast.Parameter {
+	return &ast.Parameter{Name: name}
+}
+
+// optional declara un parámetro de una intrínseca con valor por defecto def.
+func optional(name string, def Value) *ast.Parameter {
+	return &ast.Parameter{Name: name, Default: &ast.LiteralExpr{Value: def}}
+}
+
+// maxListLength limita el tamaño de las listas que crean range y la
+// replicación con '*', para no agotar la memoria.
+const maxListLength = 1 << 24
+
+// intrinsicRange implementa range(start, end=0, step): la lista de números
+// desde start hasta end inclusive. Sin step avanza de a 1 o -1 según la
+// dirección; si step apunta en la dirección contraria la lista queda vacía.
+func intrinsicRange(args []Value) (Value, error) {
+	var nums [3]float64
+	for idx, name := range []string{"start", "end", "step"} {
+		if idx == 2 && args[idx] == nil {
+			nums[2] = 1
+			if nums[1] < nums[0] {
+				nums[2] = -1
+			}
+			break
+		}
+		n, ok := args[idx].(float64)
+		if !ok {
+			return nil, &RuntimeError{Message: fmt.Sprintf("range: '%s' debe ser un número, se obtuvo %s", name, typeName(args[idx]))}
+		}
+		nums[idx] = n
+	}
+	start, end, step := nums[0], nums[1], nums[2]
+	if step == 0 {
+		return nil, &RuntimeError{Message: "range: 'step' no puede ser 0"}
+	}
+	count := stepCount(start, end, step)
+	if count > maxListLength {
+		return nil, &RuntimeError{Message: fmt.Sprintf("range: la lista tendría más de %d elementos", maxListLength)}
+	}
+	elems := make([]Value, int(count))
+	for k := range elems {
+		elems[k] = start + float64(k)*step
+	}
+	return &List{Elements: elems}, nil
+}
+
+// stepCount devuelve cuántos valores start + k*step caen entre start y end,
+// inclusive; step no puede ser 0. Calcular la cantidad de antemano, con una
+// pequeña tolerancia, evita que los errores de redondeo de sumar step
+// repetidamente agreguen o pierdan el último valor (como en 0 a 0.3 de a 0.1).
+func stepCount(start, end, step float64) float64 {
+	steps := math.Floor((end-start)/step + 1e-9)
+	if steps < 0 || math.IsNaN(steps) {
+		return 0
+	}
+	return steps + 1
+}
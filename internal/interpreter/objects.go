@@ -0,0 +1,55 @@
+package interpreter
+
+import "fmt"
+
+// isaKey es la clave que enlaza un mapa con su prototipo (clase padre).
+const isaKey = "__isa"
+
+// maxIsaDepth limita el recorrido de la cadena de prototipos para cortar ciclos.
+const maxIsaDepth = 256
+
+// newInstance implementa 'new parent': un mapa vacío cuyo __isa es parent.
+func newInstance(parent Value) (Value, error) {
+	if _, ok := parent.(*Map); !ok {
+		return nil, &RuntimeError{Message: fmt.Sprintf("'new' requiere un mapa, se obtuvo %s", typeName(parent))}
+	}
+	m := NewMap()
+	m.Set(isaKey, parent)
+	return m, nil
+}
+
+// lookupMember busca key en m y, si no está, en su cadena de __isa.
+// owner es el mapa donde se encontró la clave.
+func lookupMember(m *Map, key Value) (v Value, owner *Map, found bool) {
+	for depth := 0; m != nil && depth < maxIsaDepth; depth++ {
+		if v, ok := m.Get(key); ok {
+			return v, m, true
+		}
+		parent, _ := m.Get(isaKey)
+		m, _ = parent.(*Map)
+	}
+	return nil, nil, false
+}
+
+// isaOp implementa 'value isa class': true si class aparece en la cadena de
+// __isa de value.
+func isaOp(value, class Value) bool {
+	m, ok := value.(*Map)
+	if !ok {
+		return false
+	}
+	target, ok := class.(*Map)
+	if !ok {
+		return false
+	}
+	for depth := 0; depth < maxIsaDepth; depth++ {
+		parent, _ := m.Get(isaKey)
+		if m, ok = parent.(*Map); !ok {
+			return false
+		}
+		if m == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,273 @@
+package interpreter
+
+import (
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/DAlfaroV/miniscript/internal/parser/ast"
+)
+
+// Value es cualquier valor de MiniScript en tiempo de ejecución:
+// nil, bool, float64, string, *List, *Map o *Function.
+type Value interface{}
+
+// List es una lista de MiniScript. Se maneja siempre por puntero, así que
+// asignarla o pasarla como argumento comparte la misma lista.
+type List struct {
+	Elements []Value
+}
+
+// Map es un mapa de MiniScript. Como List, se comparte por referencia.
+// Conserva el orden de inserción para que imprimirlo o recorrerlo sea determinista.
+type Map struct {
+	keys    []Value
+	entries map[Value]Value
+}
+
+// NewMap crea un mapa vacío.
+func NewMap() *Map {
+	return &Map{entries: map[Value]Value{}}
+}
+
+// Get devuelve el valor asociado a key y si existe.
+func (m *Map) Get(key Value) (Value, bool) {
+	v, ok := m.entries[key]
+	return v, ok
+}
+
+// Set asocia key con v, agregando la clave al final si es nueva.
+func (m *Map) Set(key, v Value) {
+	if _, ok := m.entries[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.entries[key] = v
+}
+
+// Len devuelve la cantidad de entradas.
+func (m *Map) Len() int {
+	return len(m.keys)
+}
+
+// Keys devuelve las claves en orden de inserción.
+func (m *Map) Keys() []Value {
+	return m.keys
+}
+
+// Function es una función de usuario, con nombre o anónima, junto al ámbito
+// donde se definió (su clausura), o una función intrínseca implementada en Go.
+type Function struct {
+	Name       string // Vacío en funciones anónimas
+	Parameters []*ast.Parameter
+	Body       []ast.Statement
+	Closure    *Environment
+
+	// Native, si no es nil, reemplaza a Body: recibe un valor por parámetro,
+	// con los valores por defecto ya aplicados.
+	Native func(args []Value) (Value, error)
+}
+
+// displayName devuelve el nombre de la función para los mensajes de error.
+func (f *Function) displayName() string {
+	if f.Name == "" {
+		return "función anónima"
+	}
+	return "'" + f.Name + "'"
+}
+
+// isTruthy aplica la veracidad de MiniScript: nil, false, 0 y "" son falsos.
+func isTruthy(v Value) bool {
+	switch val := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return val
+	case float64:
+		return val != 0
+	case string:
+		return val != ""
+	case *List:
+		return len(val.Elements) > 0
+	case *Map:
+		return val.Len() > 0
+	default:
+		return true
+	}
+}
+
+// valuesEqual compara dos valores; valores de tipos distintos nunca son iguales
+// y las listas y mapas se comparan por contenido.
+func valuesEqual(a, b Value) bool {
+	return equal(a, b, nil)
+}
+
+// equal implementa valuesEqual. open son los pares de listas o mapas que se
+// están comparando: una misma colección es igual a sí misma, y volver a un
+// par abierto (colecciones que se contienen a sí mismas) da false en lugar de
+// no terminar.
+func equal(a, b Value, open [][2]Value) bool {
+	switch x := a.(type) {
+	case nil:
+		return b == nil
+	case bool:
+		y, ok := b.(bool)
+		return ok && x == y
+	case float64:
+		y, ok := b.(float64)
+		return ok && x == y
+	case string:
+		y, ok := b.(string)
+		return ok && x == y
+	case *List:
+		y, ok := b.(*List)
+		if ok && x == y {
+			return true
+		}
+		if !ok || len(x.Elements) != len(y.Elements) || slices.Contains(open, [2]Value{x, y}) {
+			return false
+		}
+		open = append(open, [2]Value{x, y})
+		for i := range x.Elements {
+			if !equal(x.Elements[i], y.Elements[i], open) {
+				return false
+			}
+		}
+		return true
+	case *Map:
+		y, ok := b.(*Map)
+		if ok && x == y {
+			return true
+		}
+		if !ok || x.Len() != y.Len() || slices.Contains(open, [2]Value{x, y}) {
+			return false
+		}
+		open = append(open, [2]Value{x, y})
+		for _, key := range x.keys {
+			other, found := y.Get(key)
+			if !found || !equal(x.entries[key], other, open) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
+
+// typeName devuelve el nombre del tipo de v para los mensajes de error.
+func typeName(v Value) string {
+	switch v.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		return "bool"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case *List:
+		return "list"
+	case *Map:
+		return "map"
+	case *Function:
+		return "function"
+	default:
+		return "unknown"
+	}
+}
+
+// stringify convierte un valor a su representación textual para print y concatenación.
+func stringify(v Value) string {
+	return format(v, nil)
+}
+
+// format implementa stringify. open son las listas y mapas que se están
+// mostrando; una colección que se contiene a sí misma, como un mapa cuyo
+// __isa vuelve a él, se muestra como [...] o {...}.
+func format(v Value, open []Value) string {
+	switch val := v.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		if val {
+			return "true"
+		}
+		return "false"
+	case float64:
+		return formatNumber(val)
+	case string:
+		return val
+	case *List:
+		if slices.Contains(open, v) {
+			return "[...]"
+		}
+		open = append(open, v)
+		parts := make([]string, len(val.Elements))
+		for i, elem := range val.Elements {
+			parts[i] = formatElem(elem, open)
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	case *Map:
+		if slices.Contains(open, v) {
+			return "{...}"
+		}
+		open = append(open, v)
+		parts := make([]string, 0, val.Len())
+		for _, key := range val.keys {
+			parts = append(parts, formatElem(key, open)+": "+formatElem(val.entries[key], open))
+		}
+		return "{" + strings.Join(parts, ", ") + "}"
+	case *Function:
+		params := make([]string, len(val.Parameters))
+		for i, param := range val.Parameters {
+			params[i] = formatParameter(param)
+		}
+		return "FUNCTION(" + strings.Join(params, ", ") + ")"
+	default:
+		return "<?>"
+	}
+}
+
+// formatParameter muestra un parámetro como en su declaración; de los
+// valores por defecto solo se muestran los literales.
+func formatParameter(param *ast.Parameter) string {
+	switch {
+	case param.Variadic:
+		return param.Name + "..."
+	case param.Default != nil:
+		if lit, ok := param.Default.(*ast.LiteralExpr); ok {
+			return param.Name + "=" + repr(lit.Value)
+		}
+		return param.Name + "=…"
+	default:
+		return param.Name
+	}
+}
+
+// repr es como stringify pero muestra las cadenas entre comillas; se usa
+// para los elementos de las colecciones.
+func repr(v Value) string {
+	return formatElem(v, nil)
+}
+
+// formatElem implementa repr con las colecciones abiertas de format.
+func formatElem(v Value, open []Value) string {
+	if s, ok := v.(string); ok {
+		return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+	}
+	return format(v, open)
+}
+
+// formatNumber imprime enteros sin decimales y el resto con hasta 6 decimales.
+func formatNumber(f float64) string {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	if f == math.Trunc(f) && math.Abs(f) < 1e15 {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	s := strconv.FormatFloat(f, 'f', 6, 64)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
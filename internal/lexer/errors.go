@@ -1,13 +1,37 @@
 package lexer
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 type LexError struct {
 	Message string
 	Line    int
 	Column  int
+	Span    Span // Rango del texto que provocó el error
 }
 
 func (e *LexError) Error() string {
 	return fmt.Sprintf("[LexError Line:%d Col:%d] %s", e.Line, e.Column, e.Message)
 }
+
+// ErrorList agrupa los LexError acumulados en modo de recuperación.
+type ErrorList []*LexError
+
+func (l ErrorList) Error() string {
+	msgs := make([]string, len(l))
+	for i, e := range l {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap permite usar errors.As/errors.Is sobre cada LexError de la lista.
+func (l ErrorList) Unwrap() []error {
+	errs := make([]error, len(l))
+	for i, e := range l {
+		errs[i] = e
+	}
+	return errs
+}
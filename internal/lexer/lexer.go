@@ -4,83 +4,176 @@ import (
 	"strconv"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 // Lexer realiza el análisis léxico sobre el texto fuente de MiniScript.
 type Lexer struct {
 	source  string  // Texto completo a escanear
 	tokens  []Token // Lista de tokens generados
-	start   int     // Índice de inicio del lexema actual
-	current int     // Índice del carácter actual
+	start   int     // Índice (en bytes) de inicio del lexema actual
+	current int     // Índice (en bytes) del carácter actual
 	line    int     // Línea actual en el texto (comienza en 1)
 	column  int     // Columna actual en la línea (comienza en 1)
+
+	startLine   int // Línea donde empieza el lexema actual
+	startColumn int // Columna donde empieza el lexema actual
+
+	recoverErrors bool     // Si es true, los errores se acumulan en lugar de abortar
+	escapes       bool     // Si es true, las cadenas aceptan secuencias de escape
+	keepTrivia    bool     // Si es true, se conservan espacios y comentarios como trivia
+	trivia        []Trivia // Trivia pendiente de asignar al próximo token
+
+	// nesting cuenta los '(', '[' y '{' abiertos; dentro de ellos los saltos
+	// de línea no terminan sentencias. Cada 'function' abre un nivel nuevo
+	// para que el cuerpo de una función pasada como argumento sí los tenga.
+	nesting []int
+	errors  ErrorList // Errores acumulados en modo de recuperación
+}
+
+// Option configura un Lexer al crearlo con NewLexer.
+type Option func(*Lexer)
+
+// WithErrorRecovery hace que ScanTokens registre cada LexError, emita un
+// TOKEN_ILLEGAL en su lugar y continúe hasta el final de la fuente.
+func WithErrorRecovery() Option {
+	return func(l *Lexer) {
+		l.recoverErrors = true
+	}
+}
+
+// WithEscapes habilita las secuencias de escape en cadenas: '\n', '\t',
+// '\r', '\0', '\\', '\"', '\{', '\}' y '\uXXXX'.
+func WithEscapes() Option {
+	return func(l *Lexer) {
+		l.escapes = true
+	}
+}
+
+// WithTrivia conserva espacios, saltos de línea y comentarios como
+// LeadingTrivia del token siguiente, para poder reconstruir la fuente.
+func WithTrivia() Option {
+	return func(l *Lexer) {
+		l.keepTrivia = true
+	}
 }
 
 // NewLexer crea una nueva instancia de Lexer inicializada.
-func NewLexer(source string) *Lexer {
-	return &Lexer{
+func NewLexer(source string, opts ...Option) *Lexer {
+	l := &Lexer{
 		source:  source,
 		tokens:  []Token{},
 		start:   0,
 		current: 0,
 		line:    1,
 		column:  1,
+
+		startLine:   1,
+		startColumn: 1,
+
+		nesting: []int{0},
 	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
 }
 
 // ScanTokens recorre todo el texto y devuelve la lista de tokens o un error léxico.
+// En modo de recuperación siempre devuelve la lista completa de tokens y, si
+// hubo problemas, un ErrorList con todos los LexError encontrados.
 func (l *Lexer) ScanTokens() ([]Token, error) {
 	for !l.isAtEnd() {
 		l.start = l.current
+		l.startLine = l.line
+		l.startColumn = l.column
 		if err := l.scanToken(); err != nil {
-			return nil, err
+			if lexErr, ok := err.(*LexError); ok && lexErr.Span == (Span{}) {
+				lexErr.Span = l.currentSpan()
+			}
+			if !l.recoverErrors {
+				return nil, err
+			}
+			l.recordError(err)
 		}
 	}
 	// Al completar, agregar token EOF
-	l.tokens = append(l.tokens, Token{
-		Type:    TOKEN_EOF,
-		Lexeme:  "",
-		Literal: nil,
-		Line:    l.line,
-		Column:  l.column,
-	})
+	l.start = l.current
+	l.startLine = l.line
+	l.startColumn = l.column
+	l.addToken(TOKEN_EOF)
+	if len(l.errors) > 0 {
+		return l.tokens, l.errors
+	}
 	return l.tokens, nil
 }
 
+// Errors devuelve los errores acumulados en modo de recuperación.
+func (l *Lexer) Errors() ErrorList {
+	return l.errors
+}
+
+// recordError guarda err y emite un TOKEN_ILLEGAL con el texto que lo provocó.
+func (l *Lexer) recordError(err error) {
+	lexErr, ok := err.(*LexError)
+	if !ok {
+		lexErr = &LexError{Message: err.Error(), Line: l.startLine, Column: l.startColumn, Span: l.currentSpan()}
+	}
+	l.errors = append(l.errors, lexErr)
+	l.addTokenLiteral(TOKEN_ILLEGAL, lexErr.Message)
+}
+
 // scanToken procesa un solo token a partir del carácter en l.current.
 func (l *Lexer) scanToken() error {
 	ch := l.advance()
 	switch ch {
 	case ' ', '\r', '\t':
-		// Ignorar espacios y tabulaciones
+		// Agrupar espacios y tabulaciones consecutivos
+		for l.peek() == ' ' || l.peek() == '\r' || l.peek() == '\t' {
+			l.advance()
+		}
+		l.addTrivia(TRIVIA_WHITESPACE)
 		return nil
 	case '\n':
 		l.line++
 		l.column = 1
+		if l.continuesLine() {
+			l.addTrivia(TRIVIA_NEWLINE)
+		} else {
+			l.addToken(TOKEN_NEWLINE)
+		}
 		return nil
 	case '/':
 		if l.match('/') {
+			kind := TRIVIA_COMMENT
+			if l.peek() == '/' && l.peekNext() != '/' {
+				kind = TRIVIA_DOC_COMMENT
+			}
 			l.skipComment()
+			l.addTrivia(kind)
 			return nil
 		}
-		l.addToken(TOKEN_SLASH)
+		if l.match('*') {
+			return l.blockComment()
+		}
+		l.addOperator(TOKEN_SLASH, TOKEN_SLASH_ASSIGN)
 		return nil
 	case '"':
 		return l.string()
 	case '+':
-		l.addToken(TOKEN_PLUS)
+		l.addOperator(TOKEN_PLUS, TOKEN_PLUS_ASSIGN)
 		return nil
 	case '-':
-		l.addToken(TOKEN_MINUS)
+		l.addOperator(TOKEN_MINUS, TOKEN_MINUS_ASSIGN)
 		return nil
 	case '*':
-		l.addToken(TOKEN_ASTERISK)
+		l.addOperator(TOKEN_ASTERISK, TOKEN_ASTERISK_ASSIGN)
 		return nil
 	case '%':
-		l.addToken(TOKEN_PERCENT)
+		l.addOperator(TOKEN_PERCENT, TOKEN_PERCENT_ASSIGN)
 		return nil
 	case '^':
-		l.addToken(TOKEN_CARET)
+		l.addOperator(TOKEN_CARET, TOKEN_CARET_ASSIGN)
 		return nil
 	case '(':
 		l.addToken(TOKEN_LPAREN)
@@ -107,19 +200,31 @@ func (l *Lexer) scanToken() error {
 		l.addToken(TOKEN_COLON)
 		return nil
 	case '.':
+		if isDigit(l.peek()) {
+			return l.number(ch)
+		}
+		if l.peek() == '.' && l.peekNext() == '.' {
+			l.advance()
+			l.advance()
+			l.addToken(TOKEN_ELLIPSIS)
+			return nil
+		}
 		l.addToken(TOKEN_DOT)
 		return nil
 	case ';':
 		l.addToken(TOKEN_SEMICOLON)
 		return nil
+	case '@':
+		l.addToken(TOKEN_AT)
+		return nil
 	case '!':
 		if l.match('=') {
 			l.addToken(TOKEN_NEQ)
 		} else {
 			return &LexError{
 				Message: "Unexpected character '!' (did you mean '!=')",
-				Line:    l.line,
-				Column:  l.column,
+				Line:    l.startLine,
+				Column:  l.startColumn,
 			}
 		}
 		return nil
@@ -146,39 +251,41 @@ func (l *Lexer) scanToken() error {
 		return nil
 	default:
 		if isDigit(ch) {
-			l.number()
-			return nil
+			return l.number(ch)
 		} else if isAlpha(ch) {
 			l.identifier()
 			return nil
+		} else if ch == utf8.RuneError {
+			return &LexError{
+				Message: "Invalid UTF-8 encoding",
+				Line:    l.startLine,
+				Column:  l.startColumn,
+			}
 		} else {
 			return &LexError{
 				Message: "Unexpected character '" + string(ch) + "'",
-				Line:    l.line,
-				Column:  l.column,
+				Line:    l.startLine,
+				Column:  l.startColumn,
 			}
 		}
 	}
 }
 
-// advance consume el carácter actual y avanza los índices de current y column.
+// advance consume el carácter actual (un rune UTF-8 completo) y avanza
+// current en bytes y column en caracteres.
 func (l *Lexer) advance() rune {
-	ch := rune(l.source[l.current])
-	l.current++
+	ch, size := utf8.DecodeRuneInString(l.source[l.current:])
+	l.current += size
 	l.column++
 	return ch
 }
 
 // match verifica si el siguiente carácter coincide con 'expected'. Si coincide, lo consume.
 func (l *Lexer) match(expected rune) bool {
-	if l.isAtEnd() {
-		return false
-	}
-	if rune(l.source[l.current]) != expected {
+	if l.isAtEnd() || l.peek() != expected {
 		return false
 	}
-	l.current++
-	l.column++
+	l.advance()
 	return true
 }
 
@@ -187,15 +294,21 @@ func (l *Lexer) peek() rune {
 	if l.isAtEnd() {
 		return '\000'
 	}
-	return rune(l.source[l.current])
+	ch, _ := utf8.DecodeRuneInString(l.source[l.current:])
+	return ch
 }
 
 // peekNext devuelve el carácter después del actual, sin consumirlo.
 func (l *Lexer) peekNext() rune {
-	if l.current+1 >= len(l.source) {
+	if l.isAtEnd() {
+		return '\000'
+	}
+	_, size := utf8.DecodeRuneInString(l.source[l.current:])
+	if l.current+size >= len(l.source) {
 		return '\000'
 	}
-	return rune(l.source[l.current+1])
+	ch, _ := utf8.DecodeRuneInString(l.source[l.current+size:])
+	return ch
 }
 
 // isAtEnd indica si se alcanzó el final de la fuente.
@@ -205,85 +318,510 @@ func (l *Lexer) isAtEnd() bool {
 
 // addToken crea un token sin valor literal y lo agrega a la lista.
 func (l *Lexer) addToken(tType TokenType) {
-	text := l.source[l.start:l.current]
-	l.tokens = append(l.tokens, Token{
-		Type:    tType,
-		Lexeme:  text,
-		Literal: nil,
-		Line:    l.line,
-		Column:  l.column,
-	})
+	l.addTokenLiteral(tType, nil)
+}
+
+// addOperator agrega el operador simple o, si le sigue '=', su versión de
+// asignación compuesta ('+' o '+=').
+func (l *Lexer) addOperator(simple, compound TokenType) {
+	if l.match('=') {
+		l.addToken(compound)
+	} else {
+		l.addToken(simple)
+	}
 }
 
 // addTokenLiteral crea un token con valor literal y lo agrega a la lista.
+// El token se ubica en la posición de inicio del lexema y su Span cubre
+// desde ahí hasta el carácter actual.
 func (l *Lexer) addTokenLiteral(tType TokenType, literal interface{}) {
 	text := l.source[l.start:l.current]
+	l.trackNesting(tType)
 	l.tokens = append(l.tokens, Token{
 		Type:    tType,
 		Lexeme:  text,
 		Literal: literal,
-		Line:    l.line,
-		Column:  l.column,
+		Line:    l.startLine,
+		Column:  l.startColumn,
+		Span:    l.currentSpan(),
+
+		LeadingTrivia: l.trivia,
+	})
+	l.trivia = nil
+}
+
+// continuationTokens son los tokens tras los que un salto de línea no
+// termina la sentencia: otro terminador, un operador o coma que espera más
+// operandos, o un delimitador de apertura.
+var continuationTokens = map[TokenType]bool{
+	TOKEN_NEWLINE:   true,
+	TOKEN_SEMICOLON: true,
+
+	TOKEN_PLUS:     true,
+	TOKEN_MINUS:    true,
+	TOKEN_ASTERISK: true,
+	TOKEN_SLASH:    true,
+	TOKEN_PERCENT:  true,
+	TOKEN_CARET:    true,
+	TOKEN_EQ:       true,
+	TOKEN_NEQ:      true,
+	TOKEN_GT:       true,
+	TOKEN_GTE:      true,
+	TOKEN_LT:       true,
+	TOKEN_LTE:      true,
+	TOKEN_AND:      true,
+	TOKEN_OR:       true,
+	TOKEN_NOT:      true,
+	TOKEN_ISA:      true,
+	TOKEN_AT:       true,
+	TOKEN_DOT:      true,
+	TOKEN_COMMA:    true,
+
+	TOKEN_ASSIGN:          true,
+	TOKEN_PLUS_ASSIGN:     true,
+	TOKEN_MINUS_ASSIGN:    true,
+	TOKEN_ASTERISK_ASSIGN: true,
+	TOKEN_SLASH_ASSIGN:    true,
+	TOKEN_PERCENT_ASSIGN:  true,
+	TOKEN_CARET_ASSIGN:    true,
+
+	TOKEN_LPAREN:   true,
+	TOKEN_LBRACKET: true,
+	TOKEN_LBRACE:   true,
+}
+
+// continuesLine indica si el salto de línea actual continúa la sentencia en
+// lugar de terminarla. Así también se colapsan las líneas en blanco.
+// Si quedó un '(', '[' o '{' sin cerrar y la línea siguiente empieza una
+// sentencia, se descarta el anidamiento para que el error no se extienda al
+// resto del archivo.
+func (l *Lexer) continuesLine() bool {
+	top := len(l.nesting) - 1
+	if l.nesting[top] > 0 && l.nextLineStartsStatement() {
+		l.nesting[top] = 0
+	}
+	if len(l.tokens) == 0 || l.nesting[top] > 0 {
+		return true
+	}
+	return continuationTokens[l.tokens[len(l.tokens)-1].Type]
+}
+
+// statementKeywords son las palabras clave que solo pueden iniciar una sentencia.
+var statementKeywords = map[TokenType]bool{
+	TOKEN_IF:       true,
+	TOKEN_ELSE:     true,
+	TOKEN_ELSEIF:   true,
+	TOKEN_END:      true,
+	TOKEN_WHILE:    true,
+	TOKEN_FOR:      true,
+	TOKEN_RETURN:   true,
+	TOKEN_BREAK:    true,
+	TOKEN_CONTINUE: true,
+	TOKEN_PRINT:    true,
+}
+
+// nextLineStartsStatement indica si la próxima línea con contenido empieza
+// con una palabra clave de sentencia. 'function nombre' declara una función;
+// 'function(' es una expresión y puede aparecer dentro de paréntesis.
+func (l *Lexer) nextLineStartsStatement() bool {
+	rest := strings.TrimLeft(l.source[l.current:], " \t\r\n")
+	end := strings.IndexFunc(rest, func(ch rune) bool { return !isAlphaNumeric(ch) })
+	if end < 0 {
+		end = len(rest)
+	}
+	tType, ok := keywords[rest[:end]]
+	if !ok {
+		return false
+	}
+	if tType == TOKEN_FUNCTION {
+		return !strings.HasPrefix(strings.TrimLeft(rest[end:], " \t"), "(")
+	}
+	return statementKeywords[tType]
+}
+
+// trackNesting actualiza nesting con el token tType a punto de agregarse.
+func (l *Lexer) trackNesting(tType TokenType) {
+	top := len(l.nesting) - 1
+	switch tType {
+	case TOKEN_LPAREN, TOKEN_LBRACKET, TOKEN_LBRACE:
+		l.nesting[top]++
+	case TOKEN_RPAREN, TOKEN_RBRACKET, TOKEN_RBRACE:
+		if l.nesting[top] > 0 {
+			l.nesting[top]--
+		}
+	case TOKEN_FUNCTION:
+		// 'end function' cierra el nivel que abrió su 'function'.
+		if n := len(l.tokens); n > 0 && l.tokens[n-1].Type == TOKEN_END {
+			if top > 0 {
+				l.nesting = l.nesting[:top]
+			}
+		} else {
+			l.nesting = append(l.nesting, 0)
+		}
+	}
+}
+
+// addTrivia guarda el lexema actual como trivia del próximo token. Los
+// comentarios '///' se conservan siempre para documentar funciones.
+func (l *Lexer) addTrivia(kind TriviaKind) {
+	if !l.keepTrivia && kind != TRIVIA_DOC_COMMENT {
+		return
+	}
+	l.trivia = append(l.trivia, Trivia{
+		Kind: kind,
+		Text: l.source[l.start:l.current],
+		Span: l.currentSpan(),
 	})
 }
 
-// skipComment avanza hasta el final de la línea, ignorando el comentario.
+// position devuelve la posición del carácter actual.
+func (l *Lexer) position() Position {
+	return Position{Line: l.line, Column: l.column, Offset: l.current}
+}
+
+// currentSpan devuelve el rango del lexema actual, desde su inicio hasta current.
+func (l *Lexer) currentSpan() Span {
+	return Span{
+		Start: Position{Line: l.startLine, Column: l.startColumn, Offset: l.start},
+		End:   Position{Line: l.line, Column: l.column, Offset: l.current},
+	}
+}
+
+// skipComment avanza hasta el final de la línea, sin consumir el salto.
 func (l *Lexer) skipComment() {
 	for l.peek() != '\n' && !l.isAtEnd() {
 		l.advance()
 	}
 }
 
-// string maneja literales de cadena entre comillas dobles.
-func (l *Lexer) string() error {
-	for l.peek() != '"' && !l.isAtEnd() {
-		if l.peek() == '\n' {
+// blockComment consume un comentario '/* ... */' ya iniciado; puede
+// abarcar varias líneas pero no se anida. Como en Go, un comentario que
+// contiene saltos de línea cuenta como uno: si corresponde, emite después un
+// TOKEN_NEWLINE sin lexema para terminar la sentencia.
+func (l *Lexer) blockComment() error {
+	startLine := l.line
+	for !(l.peek() == '*' && l.peekNext() == '/') {
+		if l.isAtEnd() {
+			return &LexError{
+				Message: "Unterminated block comment",
+				Line:    l.startLine,
+				Column:  l.startColumn,
+			}
+		}
+		if l.advance() == '\n' {
 			l.line++
 			l.column = 1
 		}
-		l.advance()
 	}
+	l.advance()
+	l.advance()
+	l.addTrivia(TRIVIA_BLOCK_COMMENT)
+	if l.line > startLine && !l.continuesLine() {
+		l.start = l.current
+		l.startLine = l.line
+		l.startColumn = l.column
+		l.addToken(TOKEN_NEWLINE)
+	}
+	return nil
+}
 
-	if l.isAtEnd() {
-		return &LexError{
-			Message: "Unterminated string literal",
-			Line:    l.line,
-			Column:  l.column,
+// string maneja literales de cadena entre comillas dobles. Dentro de la
+// cadena '""' representa una comilla, y '{{' y '}}' una llave literal de
+// apertura y de cierre; un '}' suelto también es literal. Cada '{expr}'
+// interpola una expresión; si hay alguna se emite un TOKEN_TEMPLATE en lugar
+// de un TOKEN_STRING. Con WithEscapes se aceptan además secuencias '\'.
+func (l *Lexer) string() error {
+	var parts []TemplatePart
+	var text strings.Builder
+	var firstErr error // se reporta al cerrar la cadena para no reanudar a mitad de ella
+	for {
+		if l.isAtEnd() {
+			if firstErr != nil {
+				return firstErr
+			}
+			return &LexError{
+				Message: "Unterminated string literal",
+				Line:    l.startLine,
+				Column:  l.startColumn,
+			}
+		}
+
+		ch := l.peek()
+		switch {
+		case ch == '"' && l.peekNext() == '"':
+			l.advance()
+			l.advance()
+			text.WriteRune('"')
+		case ch == '"':
+			// Consumir la comilla de cierre
+			l.advance()
+			if firstErr != nil {
+				return firstErr
+			}
+			if parts == nil {
+				l.addTokenLiteral(TOKEN_STRING, text.String())
+				return nil
+			}
+			if text.Len() > 0 {
+				parts = append(parts, TemplatePart{Text: text.String()})
+			}
+			l.addTokenLiteral(TOKEN_TEMPLATE, parts)
+			return nil
+		case ch == '{' && l.peekNext() == '{':
+			l.advance()
+			l.advance()
+			text.WriteRune('{')
+		case ch == '}' && l.peekNext() == '}':
+			l.advance()
+			l.advance()
+			text.WriteRune('}')
+		case ch == '{':
+			l.advance()
+			tokens, err := l.interpolation()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			if text.Len() > 0 {
+				parts = append(parts, TemplatePart{Text: text.String()})
+				text.Reset()
+			}
+			parts = append(parts, TemplatePart{Tokens: tokens})
+		case ch == '\\' && l.escapes:
+			r, err := l.escape()
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+			text.WriteRune(r)
+		default:
+			if l.advance() == '\n' {
+				l.line++
+				l.column = 1
+			}
+			text.WriteRune(ch)
 		}
 	}
+}
 
-	// Consumir la comilla de cierre
-	l.advance()
+// simpleEscapes asocia cada secuencia '\x' de un solo carácter con su valor.
+var simpleEscapes = map[rune]rune{
+	'n':  '\n',
+	't':  '\t',
+	'r':  '\r',
+	'0':  0,
+	'\\': '\\',
+	'"':  '"',
+	'{':  '{',
+	'}':  '}',
+}
 
-	// Extraer el contenido sin las comillas
-	raw := l.source[l.start+1 : l.current-1]
-	// Reemplazar "" por " dentro del contenido
-	value := strings.ReplaceAll(raw, `""`, `"`)
+// escape consume una secuencia de escape ('\n', '\u00e9', ...) y devuelve el
+// carácter que representa.
+func (l *Lexer) escape() (rune, error) {
+	start := l.position()
+	l.advance() // '\'
+	ch := l.peek()
+	if r, ok := simpleEscapes[ch]; ok {
+		l.advance()
+		return r, nil
+	}
+	if ch == 'u' {
+		l.advance()
+		var code rune
+		for i := 0; i < 4; i++ {
+			if !isHexDigit(l.peek()) {
+				return utf8.RuneError, l.escapeError(start, "expected 4 hexadecimal digits")
+			}
+			code = code*16 + hexValue(l.advance())
+		}
+		if !utf8.ValidRune(code) {
+			return utf8.RuneError, l.escapeError(start, "invalid code point")
+		}
+		return code, nil
+	}
+	if ch != '\n' && !l.isAtEnd() {
+		l.advance()
+	}
+	return utf8.RuneError, l.escapeError(start, "unknown escape")
+}
 
-	l.addTokenLiteral(TOKEN_STRING, value)
-	return nil
+// escapeError construye el LexError de una secuencia de escape inválida que
+// empieza en start y termina en el carácter actual.
+func (l *Lexer) escapeError(start Position, reason string) error {
+	return &LexError{
+		Message: "Invalid escape sequence '" + l.source[start.Offset:l.current] + "': " + reason,
+		Line:    start.Line,
+		Column:  start.Column,
+		Span:    Span{Start: start, End: l.position()},
+	}
 }
 
-// number maneja literales numéricas (enteros y flotantes).
-func (l *Lexer) number() {
-	for isDigit(l.peek()) {
-		l.advance()
+// interpolation escanea la expresión de un '{...}' dentro de una cadena, con
+// la llave de apertura ya consumida, y devuelve sus tokens terminados en EOF.
+// Un sub-lexer comparte la fuente, así que las posiciones de los tokens son
+// las del archivo y la expresión puede contener cadenas o mapas anidados.
+func (l *Lexer) interpolation() ([]Token, error) {
+	open := l.position()
+	sub := &Lexer{source: l.source, current: l.current, line: l.line, column: l.column, escapes: l.escapes, nesting: []int{1}}
+	defer func() {
+		l.current, l.line, l.column = sub.current, sub.line, sub.column
+	}()
+
+	depth := 0
+	for !sub.isAtEnd() {
+		sub.start = sub.current
+		sub.startLine = sub.line
+		sub.startColumn = sub.column
+		n := len(sub.tokens)
+		if err := sub.scanToken(); err != nil {
+			if lexErr, ok := err.(*LexError); ok && lexErr.Span == (Span{}) {
+				lexErr.Span = sub.currentSpan()
+			}
+			return nil, err
+		}
+		if len(sub.tokens) == n {
+			continue // espacio o comentario
+		}
+		switch sub.tokens[n].Type {
+		case TOKEN_LBRACE:
+			depth++
+		case TOKEN_RBRACE:
+			if depth > 0 {
+				depth--
+				continue
+			}
+			closing := sub.tokens[n]
+			if n == 0 {
+				return nil, &LexError{
+					Message: "Empty interpolation in string literal",
+					Line:    open.Line,
+					Column:  open.Column - 1,
+					Span:    Span{Start: open, End: closing.Span.End},
+				}
+			}
+			closing.Type = TOKEN_EOF
+			return append(sub.tokens[:n], closing), nil
+		}
+	}
+	return nil, &LexError{
+		Message: "Unterminated interpolation in string literal",
+		Line:    open.Line,
+		Column:  open.Column - 1,
 	}
-	// Verificar punto decimal
-	if l.peek() == '.' && isDigit(l.peekNext()) {
+}
+
+// number maneja literales numéricas: enteros y flotantes ('42', '3.14', '.5'),
+// notación científica ('1e6', '2.5E-3'), hexadecimales ('0xFF'), binarias
+// ('0b1010') y separadores de dígitos ('1_000_000'). first es el primer
+// carácter ya consumido: un dígito o el '.' de '.5'.
+func (l *Lexer) number(first rune) error {
+	if first == '0' {
+		switch l.peek() {
+		case 'x', 'X':
+			l.advance()
+			return l.radixNumber(16, isHexDigit, "hexadecimal")
+		case 'b', 'B':
+			l.advance()
+			return l.radixNumber(2, isBinaryDigit, "binary")
+		}
+	}
+
+	wellFormed := true
+	if first != '.' {
+		_, wellFormed = l.digitRun(isDigit, true)
+		// Verificar punto decimal
+		if l.peek() == '.' && isDigit(l.peekNext()) {
+			l.advance()
+			first = '.'
+		}
+	}
+	if first == '.' {
+		_, ok := l.digitRun(isDigit, false)
+		wellFormed = wellFormed && ok
+	}
+	if l.peek() == 'e' || l.peek() == 'E' {
 		l.advance()
-		for isDigit(l.peek()) {
+		if l.peek() == '+' || l.peek() == '-' {
 			l.advance()
 		}
+		count, ok := l.digitRun(isDigit, false)
+		if count == 0 {
+			return l.malformedNumber("exponent has no digits")
+		}
+		wellFormed = wellFormed && ok
 	}
-	raw := l.source[l.start:l.current]
+	if isAlphaNumeric(l.peek()) {
+		return l.malformedNumber("unexpected character '" + string(l.peek()) + "'")
+	}
+	if !wellFormed {
+		return l.malformedNumber("'_' must be placed between digits")
+	}
+
+	raw := strings.ReplaceAll(l.source[l.start:l.current], "_", "")
 	val, err := strconv.ParseFloat(raw, 64)
 	if err != nil {
-		// Si no se pudo convertir, marcamos como token ilegal
-		l.addTokenLiteral(TOKEN_ILLEGAL, raw)
-		return
+		return l.malformedNumber("value out of range")
 	}
 	l.addTokenLiteral(TOKEN_NUMBER, val)
+	return nil
+}
+
+// radixNumber maneja el resto de un literal '0x...' o '0b...' ya iniciado.
+func (l *Lexer) radixNumber(base int, valid func(rune) bool, name string) error {
+	count, wellFormed := l.digitRun(valid, false)
+	if isAlphaNumeric(l.peek()) {
+		return l.malformedNumber("invalid digit '" + string(l.peek()) + "' in " + name + " literal")
+	}
+	if count == 0 {
+		return l.malformedNumber(name + " literal has no digits")
+	}
+	if !wellFormed {
+		return l.malformedNumber("'_' must be placed between digits")
+	}
+
+	raw := strings.ReplaceAll(l.source[l.start+2:l.current], "_", "")
+	val, err := strconv.ParseUint(raw, base, 64)
+	if err != nil {
+		return l.malformedNumber("value out of range")
+	}
+	l.addTokenLiteral(TOKEN_NUMBER, float64(val))
+	return nil
+}
+
+// digitRun consume dígitos válidos y separadores '_'. Devuelve cuántos
+// dígitos consumió y si cada '_' quedó entre dos dígitos. prevIsDigit indica
+// si el carácter anterior al primero consumido ya era un dígito.
+func (l *Lexer) digitRun(valid func(rune) bool, prevIsDigit bool) (count int, ok bool) {
+	ok = true
+	for {
+		switch ch := l.peek(); {
+		case valid(ch):
+			count++
+			prevIsDigit = true
+		case ch == '_':
+			if !prevIsDigit || !valid(l.peekNext()) {
+				ok = false
+			}
+			prevIsDigit = false
+		default:
+			return count, ok
+		}
+		l.advance()
+	}
+}
+
+// malformedNumber consume el resto del lexema y devuelve el LexError del número mal formado.
+func (l *Lexer) malformedNumber(reason string) error {
+	for isAlphaNumeric(l.peek()) {
+		l.advance()
+	}
+	return &LexError{
+		Message: "Malformed number '" + l.source[l.start:l.current] + "': " + reason,
+		Line:    l.startLine,
+		Column:  l.startColumn,
+	}
 }
 
 // identifier maneja reconocimientos de identificadores y palabras clave.
@@ -305,7 +843,29 @@ func isDigit(ch rune) bool {
 	return ch >= '0' && ch <= '9'
 }
 
-// isAlpha retorna true si ch es letra [A-Za-z] o guión bajo _.
+// isHexDigit retorna true si ch es un dígito hexadecimal [0-9a-fA-F].
+func isHexDigit(ch rune) bool {
+	return isDigit(ch) || (ch >= 'a' && ch <= 'f') || (ch >= 'A' && ch <= 'F')
+}
+
+// hexValue devuelve el valor de un dígito hexadecimal.
+func hexValue(ch rune) rune {
+	switch {
+	case ch >= 'a':
+		return ch - 'a' + 10
+	case ch >= 'A':
+		return ch - 'A' + 10
+	default:
+		return ch - '0'
+	}
+}
+
+// isBinaryDigit retorna true si ch es 0 o 1.
+func isBinaryDigit(ch rune) bool {
+	return ch == '0' || ch == '1'
+}
+
+// isAlpha retorna true si ch es una letra Unicode o guión bajo _.
 func isAlpha(ch rune) bool {
 	return unicode.IsLetter(ch) || ch == '_'
 }
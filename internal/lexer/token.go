@@ -1,5 +1,11 @@
 package lexer
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 type TokenType int
 
 const (
@@ -9,11 +15,13 @@ const (
 	TOKEN_IDENTIFIER // nombre de variable o función
 	TOKEN_NUMBER     // literal numérico
 	TOKEN_STRING     // literal de cadena
+	TOKEN_TEMPLATE   // cadena con interpolaciones '{expr}'
 	TOKEN_TRUE       // literal booleano true
 	TOKEN_FALSE      // literal booleano false
 	TOKEN_NIL        // literal nil
 
 	TOKEN_IF
+	TOKEN_THEN
 	TOKEN_ELSE
 	TOKEN_ELSEIF
 	TOKEN_END
@@ -24,7 +32,8 @@ const (
 	TOKEN_BREAK
 	TOKEN_CONTINUE
 	TOKEN_PRINT
-	TOKEN_RANGE
+	TOKEN_IN
+	TOKEN_TO
 
 	TOKEN_PLUS     // +
 	TOKEN_MINUS    // -
@@ -40,6 +49,13 @@ const (
 	TOKEN_LTE      // <=
 	TOKEN_ASSIGN   // =
 
+	TOKEN_PLUS_ASSIGN     // +=
+	TOKEN_MINUS_ASSIGN    // -=
+	TOKEN_ASTERISK_ASSIGN // *=
+	TOKEN_SLASH_ASSIGN    // /=
+	TOKEN_PERCENT_ASSIGN  // %=
+	TOKEN_CARET_ASSIGN    // ^=
+
 	TOKEN_LPAREN    // (
 	TOKEN_RPAREN    // )
 	TOKEN_LBRACKET  // [
@@ -50,22 +66,178 @@ const (
 	TOKEN_COLON     // :
 	TOKEN_DOT       // .
 	TOKEN_SEMICOLON // ;
+	TOKEN_NEWLINE   // fin de línea que termina una sentencia
+	TOKEN_AT        // @
+	TOKEN_ELLIPSIS  // ...
 
 	TOKEN_AND
 	TOKEN_OR
 	TOKEN_NOT
+	TOKEN_NEW
+	TOKEN_ISA
 )
 
+var tokenNames = map[TokenType]string{
+	TOKEN_ILLEGAL:    "ILLEGAL",
+	TOKEN_EOF:        "EOF",
+	TOKEN_IDENTIFIER: "IDENTIFIER",
+	TOKEN_NUMBER:     "NUMBER",
+	TOKEN_STRING:     "STRING",
+	TOKEN_TEMPLATE:   "TEMPLATE",
+	TOKEN_TRUE:       "TRUE",
+	TOKEN_FALSE:      "FALSE",
+	TOKEN_NIL:        "NIL",
+	TOKEN_IF:         "IF",
+	TOKEN_THEN:       "THEN",
+	TOKEN_ELSE:       "ELSE",
+	TOKEN_ELSEIF:     "ELSEIF",
+	TOKEN_END:        "END",
+	TOKEN_WHILE:      "WHILE",
+	TOKEN_FOR:        "FOR",
+	TOKEN_FUNCTION:   "FUNCTION",
+	TOKEN_RETURN:     "RETURN",
+	TOKEN_BREAK:      "BREAK",
+	TOKEN_CONTINUE:   "CONTINUE",
+	TOKEN_PRINT:      "PRINT",
+	TOKEN_IN:         "IN",
+	TOKEN_TO:         "TO",
+	TOKEN_PLUS:       "PLUS",
+	TOKEN_MINUS:      "MINUS",
+	TOKEN_ASTERISK:   "ASTERISK",
+	TOKEN_SLASH:      "SLASH",
+	TOKEN_PERCENT:    "PERCENT",
+	TOKEN_CARET:      "CARET",
+	TOKEN_EQ:         "EQ",
+	TOKEN_NEQ:        "NEQ",
+	TOKEN_GT:         "GT",
+	TOKEN_GTE:        "GTE",
+	TOKEN_LT:         "LT",
+	TOKEN_LTE:        "LTE",
+	TOKEN_ASSIGN:     "ASSIGN",
+
+	TOKEN_PLUS_ASSIGN:     "PLUS_ASSIGN",
+	TOKEN_MINUS_ASSIGN:    "MINUS_ASSIGN",
+	TOKEN_ASTERISK_ASSIGN: "ASTERISK_ASSIGN",
+	TOKEN_SLASH_ASSIGN:    "SLASH_ASSIGN",
+	TOKEN_PERCENT_ASSIGN:  "PERCENT_ASSIGN",
+	TOKEN_CARET_ASSIGN:    "CARET_ASSIGN",
+	TOKEN_LPAREN:          "LPAREN",
+	TOKEN_RPAREN:          "RPAREN",
+	TOKEN_LBRACKET:        "LBRACKET",
+	TOKEN_RBRACKET:        "RBRACKET",
+	TOKEN_LBRACE:          "LBRACE",
+	TOKEN_RBRACE:          "RBRACE",
+	TOKEN_COMMA:           "COMMA",
+	TOKEN_COLON:           "COLON",
+	TOKEN_DOT:             "DOT",
+	TOKEN_NEWLINE:         "NEWLINE",
+	TOKEN_SEMICOLON:       "SEMICOLON",
+	TOKEN_AT:              "AT",
+	TOKEN_ELLIPSIS:        "ELLIPSIS",
+	TOKEN_AND:             "AND",
+	TOKEN_OR:              "OR",
+	TOKEN_NOT:             "NOT",
+	TOKEN_NEW:             "NEW",
+	TOKEN_ISA:             "ISA",
+}
+
+// String devuelve el nombre legible del tipo de token.
+func (t TokenType) String() string {
+	if name, ok := tokenNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("TokenType(%d)", int(t))
+}
+
 type Token struct {
 	Type    TokenType   // El tipo de token (uno de los valores de TokenType)
 	Lexeme  string      // El texto exacto extraído de la fuente
-	Literal interface{} // Valor “parseado” (float64 para números, string sin comillas, bool para true/false)
-	Line    int         // Número de línea donde apareció el token
-	Column  int         // Número de columna aproximada donde empieza el token
+	Literal interface{} // Valor “parseado” (float64 para números, string sin comillas, []TemplatePart si hay interpolación, bool para true/false)
+	Line    int         // Número de línea donde empieza el token
+	Column  int         // Número de columna (en caracteres) donde empieza el token
+	Span    Span        // Rango completo que ocupa el token en la fuente
+
+	// LeadingTrivia guarda lo que precede al token y no forma parte de la
+	// gramática. Siempre incluye los comentarios '///'; con WithTrivia incluye
+	// además espacios, saltos de línea y comentarios comunes.
+	LeadingTrivia []Trivia
+}
+
+// TriviaKind clasifica los fragmentos de Trivia.
+type TriviaKind int
+
+const (
+	TRIVIA_WHITESPACE    TriviaKind = iota // espacios, tabulaciones y '\r'
+	TRIVIA_NEWLINE                         // '\n'
+	TRIVIA_COMMENT                         // '// ...'
+	TRIVIA_BLOCK_COMMENT                   // '/* ... */'
+	TRIVIA_DOC_COMMENT                     // '/// ...'
+)
+
+var triviaNames = map[TriviaKind]string{
+	TRIVIA_WHITESPACE:    "WHITESPACE",
+	TRIVIA_NEWLINE:       "NEWLINE",
+	TRIVIA_COMMENT:       "COMMENT",
+	TRIVIA_BLOCK_COMMENT: "BLOCK_COMMENT",
+	TRIVIA_DOC_COMMENT:   "DOC_COMMENT",
+}
+
+// String devuelve el nombre legible del tipo de trivia.
+func (k TriviaKind) String() string {
+	if name, ok := triviaNames[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("TriviaKind(%d)", int(k))
+}
+
+// Trivia es un fragmento de la fuente sin significado sintáctico. Concatenar
+// la trivia y el lexema de cada token reproduce la fuente original.
+type Trivia struct {
+	Kind TriviaKind
+	Text string // Texto exacto, incluidos los delimitadores del comentario
+	Span Span
+}
+
+// TemplatePart es un fragmento de un TOKEN_TEMPLATE: texto literal o, si
+// Tokens no es nil, los tokens de una interpolación '{...}' terminados en EOF.
+type TemplatePart struct {
+	Text   string
+	Tokens []Token
+}
+
+// String muestra el fragmento como texto entre comillas o como '{...}'.
+func (p TemplatePart) String() string {
+	if p.Tokens == nil {
+		return strconv.Quote(p.Text)
+	}
+	lexemes := make([]string, 0, len(p.Tokens)-1)
+	for _, tok := range p.Tokens[:len(p.Tokens)-1] {
+		lexemes = append(lexemes, tok.Lexeme)
+	}
+	return "{" + strings.Join(lexemes, " ") + "}"
+}
+
+// Position ubica un punto de la fuente.
+type Position struct {
+	Line   int // Línea (comienza en 1)
+	Column int // Columna en caracteres (comienza en 1)
+	Offset int // Desplazamiento en bytes desde el inicio de la fuente
+}
+
+// Span es el rango [Start, End) que ocupa un token o nodo en la fuente.
+type Span struct {
+	Start Position
+	End   Position
+}
+
+// Join devuelve el rango que va desde el inicio de s hasta el final de other.
+func (s Span) Join(other Span) Span {
+	return Span{Start: s.Start, End: other.End}
 }
 
 var keywords = map[string]TokenType{
 	"if":       TOKEN_IF,
+	"then":     TOKEN_THEN,
 	"else":     TOKEN_ELSE,
 	"elseif":   TOKEN_ELSEIF,
 	"end":      TOKEN_END,
@@ -76,11 +248,14 @@ var keywords = map[string]TokenType{
 	"break":    TOKEN_BREAK,
 	"continue": TOKEN_CONTINUE,
 	"print":    TOKEN_PRINT,
-	"range":    TOKEN_RANGE,
+	"in":       TOKEN_IN,
+	"to":       TOKEN_TO,
 	"true":     TOKEN_TRUE,
 	"false":    TOKEN_FALSE,
 	"nil":      TOKEN_NIL,
 	"and":      TOKEN_AND,
 	"or":       TOKEN_OR,
 	"not":      TOKEN_NOT,
+	"new":      TOKEN_NEW,
+	"isa":      TOKEN_ISA,
 }
@@ -0,0 +1,345 @@
+package ast
+
+import "github.com/DAlfaroV/miniscript/internal/lexer"
+
+type Node interface {
+	NodeType() string
+	NodeSpan() lexer.Span // Rango de la fuente que cubre el nodo
+}
+
+type Statement interface {
+	Node
+	isStatement()
+}
+
+type Expression interface {
+	Node
+	isExpression()
+}
+
+type Program struct {
+	Statements []Statement
+	Span       lexer.Span
+}
+
+func (p *Program) NodeType() string     { return "Program" }
+func (p *Program) NodeSpan() lexer.Span { return p.Span }
+
+type ExpressionStmt struct {
+	Expr Expression
+	Span lexer.Span
+}
+
+func (s *ExpressionStmt) NodeType() string     { return "ExpressionStmt" }
+func (s *ExpressionStmt) NodeSpan() lexer.Span { return s.Span }
+func (s *ExpressionStmt) isStatement()         {}
+
+type PrintStmt struct {
+	Value Expression
+	Span  lexer.Span
+}
+
+func (s *PrintStmt) NodeType() string     { return "PrintStmt" }
+func (s *PrintStmt) NodeSpan() lexer.Span { return s.Span }
+func (s *PrintStmt) isStatement()         {}
+
+type AssignmentStmt struct {
+	Name     string
+	Operator string // "=" o un operador compuesto como "+="
+	Value    Expression
+	Span     lexer.Span
+}
+
+func (s *AssignmentStmt) NodeType() string     { return "AssignmentStmt" }
+func (s *AssignmentStmt) NodeSpan() lexer.Span { return s.Span }
+func (s *AssignmentStmt) isStatement()         {}
+
+// IndexAssignStmt es la asignación 'Object[Index] = Value' (o '+=', '-=', ...).
+type IndexAssignStmt struct {
+	Object   Expression
+	Index    Expression
+	Operator string
+	Value    Expression
+	Span     lexer.Span
+}
+
+func (s *IndexAssignStmt) NodeType() string     { return "IndexAssignStmt" }
+func (s *IndexAssignStmt) NodeSpan() lexer.Span { return s.Span }
+func (s *IndexAssignStmt) isStatement()         {}
+
+// MemberAssignStmt es la asignación 'Object.Name = Value' (o '+=', '-=', ...).
+type MemberAssignStmt struct {
+	Object   Expression
+	Name     string
+	Operator string
+	Value    Expression
+	Span     lexer.Span
+}
+
+func (s *MemberAssignStmt) NodeType() string     { return "MemberAssignStmt" }
+func (s *MemberAssignStmt) NodeSpan() lexer.Span { return s.Span }
+func (s *MemberAssignStmt) isStatement()         {}
+
+type IfStmt struct {
+	Condition   Expression
+	ThenBlock   []Statement
+	ElseIfConds []Expression
+	ElseIfBods  [][]Statement
+	ElseBlock   []Statement
+	Span        lexer.Span
+}
+
+func (s *IfStmt) NodeType() string     { return "IfStmt" }
+func (s *IfStmt) NodeSpan() lexer.Span { return s.Span }
+func (s *IfStmt) isStatement()         {}
+
+type WhileStmt struct {
+	Label     string // Etiqueta opcional: 'nombre: while ...'
+	Condition Expression
+	Body      []Statement
+	Span      lexer.Span
+}
+
+func (s *WhileStmt) NodeType() string     { return "WhileStmt" }
+func (s *WhileStmt) NodeSpan() lexer.Span { return s.Span }
+func (s *WhileStmt) isStatement()         {}
+
+type ForStmt struct {
+	Label     string // Etiqueta opcional: 'nombre: for ...'
+	VarName   string
+	StartExpr Expression
+	EndExpr   Expression
+	StepExpr  Expression // nil si no hay cláusula 'step'
+	Body      []Statement
+	Span      lexer.Span
+}
+
+func (s *ForStmt) NodeType() string     { return "ForStmt" }
+func (s *ForStmt) NodeSpan() lexer.Span { return s.Span }
+func (s *ForStmt) isStatement()         {}
+
+// ForInStmt recorre los elementos de una lista, los caracteres de una cadena
+// o los pares clave/valor de un mapa: 'for VarName in Iterable'.
+type ForInStmt struct {
+	Label    string // Etiqueta opcional: 'nombre: for ...'
+	VarName  string
+	Iterable Expression
+	Body     []Statement
+	Span     lexer.Span
+}
+
+func (s *ForInStmt) NodeType() string     { return "ForInStmt" }
+func (s *ForInStmt) NodeSpan() lexer.Span { return s.Span }
+func (s *ForInStmt) isStatement()         {}
+
+type FunctionStmt struct {
+	Name       string
+	Doc        string // Texto de los comentarios '///' que preceden a la función
+	Parameters []*Parameter
+	Body       []Statement
+	Span       lexer.Span
+}
+
+func (s *FunctionStmt) NodeType() string     { return "FunctionStmt" }
+func (s *FunctionStmt) NodeSpan() lexer.Span { return s.Span }
+func (s *FunctionStmt) isStatement()         {}
+
+// Parameter es un parámetro de función. Default es nil si el parámetro es
+// obligatorio; Variadic marca el último parámetro 'resto...', que recibe en
+// una lista los argumentos sobrantes.
+type Parameter struct {
+	Name     string
+	Default  Expression
+	Variadic bool
+	Span     lexer.Span
+}
+
+func (p *Parameter) NodeType() string     { return "Parameter" }
+func (p *Parameter) NodeSpan() lexer.Span { return p.Span }
+
+type ReturnStmt struct {
+	Value Expression
+	Span  lexer.Span
+}
+
+func (s *ReturnStmt) NodeType() string     { return "ReturnStmt" }
+func (s *ReturnStmt) NodeSpan() lexer.Span { return s.Span }
+func (s *ReturnStmt) isStatement()         {}
+
+// BreakStmt termina el ciclo más interno o, con Label, el ciclo con esa etiqueta.
+type BreakStmt struct {
+	Label string
+	Span  lexer.Span
+}
+
+func (s *BreakStmt) NodeType() string     { return "BreakStmt" }
+func (s *BreakStmt) NodeSpan() lexer.Span { return s.Span }
+func (s *BreakStmt) isStatement()         {}
+
+// ContinueStmt pasa a la siguiente vuelta del ciclo más interno o, con
+// Label, del ciclo con esa etiqueta.
+type ContinueStmt struct {
+	Label string
+	Span  lexer.Span
+}
+
+func (s *ContinueStmt) NodeType() string     { return "ContinueStmt" }
+func (s *ContinueStmt) NodeSpan() lexer.Span { return s.Span }
+func (s *ContinueStmt) isStatement()         {}
+
+type BinaryExpr struct {
+	Left     Expression
+	Operator string
+	Right    Expression
+	Span     lexer.Span
+}
+
+func (e *BinaryExpr) NodeType() string     { return "BinaryExpr" }
+func (e *BinaryExpr) NodeSpan() lexer.Span { return e.Span }
+func (e *BinaryExpr) isExpression()        {}
+
+// LogicalExpr es un 'and' u 'or'; el operando derecho se evalúa solo si hace falta.
+type LogicalExpr struct {
+	Left     Expression
+	Operator string
+	Right    Expression
+	Span     lexer.Span
+}
+
+func (e *LogicalExpr) NodeType() string     { return "LogicalExpr" }
+func (e *LogicalExpr) NodeSpan() lexer.Span { return e.Span }
+func (e *LogicalExpr) isExpression()        {}
+
+type UnaryExpr struct {
+	Operator string
+	Right    Expression
+	Span     lexer.Span
+}
+
+func (e *UnaryExpr) NodeType() string     { return "UnaryExpr" }
+func (e *UnaryExpr) NodeSpan() lexer.Span { return e.Span }
+func (e *UnaryExpr) isExpression()        {}
+
+type LiteralExpr struct {
+	Value interface{}
+	Span  lexer.Span
+}
+
+func (e *LiteralExpr) NodeType() string     { return "LiteralExpr" }
+func (e *LiteralExpr) NodeSpan() lexer.Span { return e.Span }
+func (e *LiteralExpr) isExpression()        {}
+
+// InterpolatedStringExpr es una cadena con interpolaciones como "Total: {n}".
+// Parts alterna textos (LiteralExpr) y las expresiones interpoladas.
+type InterpolatedStringExpr struct {
+	Parts []Expression
+	Span  lexer.Span
+}
+
+func (e *InterpolatedStringExpr) NodeType() string     { return "InterpolatedStringExpr" }
+func (e *InterpolatedStringExpr) NodeSpan() lexer.Span { return e.Span }
+func (e *InterpolatedStringExpr) isExpression()        {}
+
+type VariableExpr struct {
+	Name string
+	Span lexer.Span
+}
+
+func (e *VariableExpr) NodeType() string     { return "VariableExpr" }
+func (e *VariableExpr) NodeSpan() lexer.Span { return e.Span }
+func (e *VariableExpr) isExpression()        {}
+
+type GroupingExpr struct {
+	Expression Expression
+	Span       lexer.Span
+}
+
+func (e *GroupingExpr) NodeType() string     { return "GroupingExpr" }
+func (e *GroupingExpr) NodeSpan() lexer.Span { return e.Span }
+func (e *GroupingExpr) isExpression()        {}
+
+type CallExpr struct {
+	Callee    Expression
+	Arguments []Expression
+	Span      lexer.Span
+}
+
+func (e *CallExpr) NodeType() string     { return "CallExpr" }
+func (e *CallExpr) NodeSpan() lexer.Span { return e.Span }
+func (e *CallExpr) isExpression()        {}
+
+// MemberExpr es el acceso 'Object.Name'.
+type MemberExpr struct {
+	Object Expression
+	Name   string
+	Span   lexer.Span
+}
+
+func (e *MemberExpr) NodeType() string     { return "MemberExpr" }
+func (e *MemberExpr) NodeSpan() lexer.Span { return e.Span }
+func (e *MemberExpr) isExpression()        {}
+
+// ListExpr es un literal de lista '[a, b, c]'.
+type ListExpr struct {
+	Elements []Expression
+	Span     lexer.Span
+}
+
+func (e *ListExpr) NodeType() string     { return "ListExpr" }
+func (e *ListExpr) NodeSpan() lexer.Span { return e.Span }
+func (e *ListExpr) isExpression()        {}
+
+// MapExpr es un literal de mapa '{k1: v1, k2: v2}'; Keys[i] corresponde a Values[i].
+type MapExpr struct {
+	Keys   []Expression
+	Values []Expression
+	Span   lexer.Span
+}
+
+func (e *MapExpr) NodeType() string     { return "MapExpr" }
+func (e *MapExpr) NodeSpan() lexer.Span { return e.Span }
+func (e *MapExpr) isExpression()        {}
+
+// IndexExpr es el acceso 'Object[Index]'; los índices negativos cuentan desde el final.
+type IndexExpr struct {
+	Object Expression
+	Index  Expression
+	Span   lexer.Span
+}
+
+func (e *IndexExpr) NodeType() string     { return "IndexExpr" }
+func (e *IndexExpr) NodeSpan() lexer.Span { return e.Span }
+func (e *IndexExpr) isExpression()        {}
+
+// SliceExpr es el corte 'Object[Start:End]'; Start y End pueden ser nil.
+type SliceExpr struct {
+	Object Expression
+	Start  Expression
+	End    Expression
+	Span   lexer.Span
+}
+
+func (e *SliceExpr) NodeType() string     { return "SliceExpr" }
+func (e *SliceExpr) NodeSpan() lexer.Span { return e.Span }
+func (e *SliceExpr) isExpression()        {}
+
+// FunctionExpr es una función anónima 'function(a, b) ... end function'.
+type FunctionExpr struct {
+	Parameters []*Parameter
+	Body       []Statement
+	Span       lexer.Span
+}
+
+func (e *FunctionExpr) NodeType() string     { return "FunctionExpr" }
+func (e *FunctionExpr) NodeSpan() lexer.Span { return e.Span }
+func (e *FunctionExpr) isExpression()        {}
+
+// AddressOfExpr es '@f': obtiene la función sin invocarla.
+type AddressOfExpr struct {
+	Expr Expression
+	Span lexer.Span
+}
+
+func (e *AddressOfExpr) NodeType() string     { return "AddressOfExpr" }
+func (e *AddressOfExpr) NodeSpan() lexer.Span { return e.Span }
+func (e *AddressOfExpr) isExpression()        {}
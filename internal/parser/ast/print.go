@@ -0,0 +1,99 @@
+package ast
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// Fprint escribe en w una representación indentada del árbol que cuelga de node.
+func Fprint(w io.Writer, node Node) error {
+	pr := &printer{w: w}
+	pr.node(reflect.ValueOf(node), 0)
+	return pr.err
+}
+
+type printer struct {
+	w   io.Writer
+	err error
+}
+
+func (pr *printer) line(indent int, format string, args ...interface{}) {
+	if pr.err != nil {
+		return
+	}
+	_, pr.err = fmt.Fprintf(pr.w, "%s%s\n", strings.Repeat("  ", indent), fmt.Sprintf(format, args...))
+}
+
+// node imprime un nodo y, debajo, cada uno de sus campos exportados.
+func (pr *printer) node(v reflect.Value, indent int) {
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if !v.IsValid() || (v.Kind() == reflect.Ptr && v.IsNil()) {
+		pr.line(indent, "nil")
+		return
+	}
+	n, ok := v.Interface().(Node)
+	if !ok {
+		pr.line(indent, "%s", formatLeaf(v))
+		return
+	}
+	span := n.NodeSpan()
+	pr.line(indent, "%s %d:%d-%d:%d", n.NodeType(),
+		span.Start.Line, span.Start.Column, span.End.Line, span.End.Column)
+	st := reflect.Indirect(v)
+	if st.Kind() != reflect.Struct {
+		return
+	}
+	for idx := 0; idx < st.NumField(); idx++ {
+		field := st.Type().Field(idx)
+		// El rango ya se imprime junto al tipo de nodo.
+		if !field.IsExported() || field.Name == "Span" {
+			continue
+		}
+		pr.field(field.Name, st.Field(idx), indent+1)
+	}
+}
+
+func (pr *printer) field(name string, v reflect.Value, indent int) {
+	switch {
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8:
+		if v.Len() == 0 {
+			pr.line(indent, "%s: []", name)
+			return
+		}
+		pr.line(indent, "%s:", name)
+		for idx := 0; idx < v.Len(); idx++ {
+			pr.field(fmt.Sprintf("[%d]", idx), v.Index(idx), indent+1)
+		}
+	case isNodeValue(v):
+		pr.line(indent, "%s:", name)
+		pr.node(v, indent+1)
+	default:
+		pr.line(indent, "%s: %s", name, formatLeaf(v))
+	}
+}
+
+// isNodeValue indica si v contiene (o puede contener) un nodo del AST.
+func isNodeValue(v reflect.Value) bool {
+	nodeType := reflect.TypeOf((*Node)(nil)).Elem()
+	if v.Kind() == reflect.Interface && !v.IsNil() {
+		return v.Elem().Type().Implements(nodeType)
+	}
+	return v.Type().Implements(nodeType)
+}
+
+func formatLeaf(v reflect.Value) string {
+	if v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "nil"
+		}
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.String {
+		return fmt.Sprintf("%q", v.String())
+	}
+	return fmt.Sprintf("%v", v.Interface())
+}
@@ -0,0 +1,38 @@
+package ast
+
+import "reflect"
+
+// Inspect recorre en profundidad el árbol que cuelga de node. Llama a f con
+// cada nodo; si f devuelve true visita sus hijos y después llama a f(nil),
+// de modo que f puede llevar la pila de nodos abiertos.
+func Inspect(node Node, f func(Node) bool) {
+	inspect(reflect.ValueOf(node), f)
+}
+
+// inspect visita v si es un nodo, o cada elemento si es una lista de nodos
+// (incluidas listas anidadas como IfStmt.ElseIfBods).
+func inspect(v reflect.Value, f func(Node) bool) {
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if !v.IsValid() || (v.Kind() == reflect.Ptr && v.IsNil()) {
+		return
+	}
+	if v.Kind() == reflect.Slice {
+		for idx := 0; idx < v.Len(); idx++ {
+			inspect(v.Index(idx), f)
+		}
+		return
+	}
+	n, ok := v.Interface().(Node)
+	if !ok || !f(n) {
+		return
+	}
+	st := reflect.Indirect(v)
+	for idx := 0; idx < st.NumField(); idx++ {
+		if st.Type().Field(idx).IsExported() {
+			inspect(st.Field(idx), f)
+		}
+	}
+	f(nil)
+}
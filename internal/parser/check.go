@@ -0,0 +1,114 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/DAlfaroV/miniscript/internal/parser/ast"
+)
+
+// Check valida el contexto de las sentencias de salto: 'break' y 'continue'
+// solo dentro de un ciclo de la misma función, con una etiqueta que nombre a
+// uno de los ciclos que los encierran, y 'return' solo dentro de una función.
+// ParseProgram lo aplica sobre cada programa; los errores se reportan como
+// ParseError ubicados en la sentencia inválida.
+func Check(prog *ast.Program) ErrorList {
+	c := &checker{}
+	ast.Inspect(prog, c.visit)
+	return c.errors
+}
+
+// checker lleva la pila de nodos abiertos durante el recorrido de Check.
+type checker struct {
+	stack  []ast.Node
+	errors ErrorList
+}
+
+func (c *checker) visit(node ast.Node) bool {
+	if node == nil {
+		c.stack = c.stack[:len(c.stack)-1]
+		return false
+	}
+	switch n := node.(type) {
+	case *ast.WhileStmt, *ast.ForStmt, *ast.ForInStmt:
+		if label := loopLabel(n); label != "" {
+			if _, found := c.enclosingLoop(label); found {
+				c.report(n, fmt.Sprintf("La etiqueta '%s' ya está en uso por un ciclo exterior", label))
+			}
+		}
+	case *ast.BreakStmt:
+		c.checkJump(n, "break", n.Label)
+	case *ast.ContinueStmt:
+		c.checkJump(n, "continue", n.Label)
+	case *ast.ReturnStmt:
+		if !c.inFunction() {
+			c.report(n, "'return' fuera de una función")
+		}
+	}
+	c.stack = append(c.stack, node)
+	return true
+}
+
+// checkJump valida un 'break' o 'continue' (keyword) con etiqueta opcional.
+func (c *checker) checkJump(node ast.Node, keyword, label string) {
+	loop, found := c.enclosingLoop(label)
+	switch {
+	case loop == nil:
+		c.report(node, fmt.Sprintf("'%s' fuera de un ciclo", keyword))
+	case !found:
+		c.report(node, fmt.Sprintf("'%s %s': no hay un ciclo con la etiqueta '%s' que lo contenga", keyword, label, label))
+	}
+}
+
+// enclosingLoop busca, desde el nodo más interno y sin salir de la función
+// actual, el ciclo con la etiqueta label (cualquiera si label es ""). loop es
+// el ciclo más interno, nil si no hay ninguno; found indica si se encontró.
+func (c *checker) enclosingLoop(label string) (loop ast.Node, found bool) {
+	for idx := len(c.stack) - 1; idx >= 0; idx-- {
+		switch n := c.stack[idx].(type) {
+		case *ast.FunctionStmt, *ast.FunctionExpr:
+			return loop, false
+		case *ast.WhileStmt, *ast.ForStmt, *ast.ForInStmt:
+			if loop == nil {
+				loop = n
+			}
+			if label == "" || loopLabel(n) == label {
+				return loop, true
+			}
+		}
+	}
+	return loop, false
+}
+
+// inFunction indica si el nodo actual está dentro del cuerpo de una función.
+func (c *checker) inFunction() bool {
+	for _, n := range c.stack {
+		switch n.(type) {
+		case *ast.FunctionStmt, *ast.FunctionExpr:
+			return true
+		}
+	}
+	return false
+}
+
+func (c *checker) report(node ast.Node, msg string) {
+	span := node.NodeSpan()
+	c.errors = append(c.errors, &ParseError{
+		Message: msg,
+		Line:    span.Start.Line,
+		Column:  span.Start.Column,
+		Span:    span,
+	})
+}
+
+// loopLabel devuelve la etiqueta de un ciclo, o "" si no tiene.
+func loopLabel(loop ast.Node) string {
+	switch n := loop.(type) {
+	case *ast.WhileStmt:
+		return n.Label
+	case *ast.ForStmt:
+		return n.Label
+	case *ast.ForInStmt:
+		return n.Label
+	}
+	return ""
+}
@@ -1,13 +1,39 @@
 package parser
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/DAlfaroV/miniscript/internal/lexer"
+)
 
 type ParseError struct {
 	Message string
 	Line    int
 	Column  int
+	Span    lexer.Span // Rango del token que provocó el error
 }
 
 func (e *ParseError) Error() string {
 	return fmt.Sprintf("[ParseError Line:%d Col:%d] %s", e.Line, e.Column, e.Message)
 }
+
+// ErrorList agrupa todos los ParseError encontrados en una pasada.
+type ErrorList []*ParseError
+
+func (l ErrorList) Error() string {
+	msgs := make([]string, len(l))
+	for i, e := range l {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap permite usar errors.As/errors.Is sobre cada ParseError de la lista.
+func (l ErrorList) Unwrap() []error {
+	errs := make([]error, len(l))
+	for i, e := range l {
+		errs[i] = e
+	}
+	return errs
+}
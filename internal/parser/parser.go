@@ -2,15 +2,17 @@ package parser
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/DAlfaroV/miniscript/internal/lexer"
-	"github.com/DAlfaroV/miniscript/internal/parser/ast.go"
+	"github.com/DAlfaroV/miniscript/internal/parser/ast"
 )
 
 // Parser convierte tokens en un AST de MiniScript.
 type Parser struct {
 	tokens  []lexer.Token
 	current int
+	errors  ErrorList // Errores acumulados durante la recuperación
 }
 
 // New crea un nuevo parser con la lista de tokens.
@@ -19,18 +21,96 @@ func New(tokens []lexer.Token) *Parser {
 }
 
 // ParseProgram construye el nodo raíz con todas las sentencias.
-func (p *Parser) ParseProgram() *ast.Program {
+// Ante un error de sintaxis se sincroniza en la siguiente sentencia y sigue
+// analizando; al final devuelve el programa parcial junto a un ErrorList con
+// todos los ParseError encontrados, incluidos los de Check.
+func (p *Parser) ParseProgram() (*ast.Program, error) {
 	prog := &ast.Program{}
-	for !p.isAtEnd() {
-		stmt := p.parseStatement()
+	first := p.peek()
+	for p.skipTerminators(); !p.isAtEnd(); p.skipTerminators() {
+		stmt := p.parseStatementRecover()
 		if stmt != nil {
 			prog.Statements = append(prog.Statements, stmt)
 		}
 	}
-	return prog
+	prog.Span = first.Span.Join(p.peek().Span)
+	p.errors = append(p.errors, Check(prog)...)
+	if len(p.errors) > 0 {
+		return prog, p.errors
+	}
+	return prog, nil
+}
+
+// parseStatementRecover analiza una sentencia junto con su terminador; si
+// falla, registra el error, descarta tokens hasta un punto de sincronización
+// y devuelve nil.
+func (p *Parser) parseStatementRecover() (stmt ast.Statement) {
+	start := p.current
+	defer func() {
+		if r := recover(); r != nil {
+			parseErr, ok := r.(*ParseError)
+			if !ok {
+				panic(r)
+			}
+			// synchronize se detiene en 'end', 'else' y 'elseif' sin consumirlos;
+			// si uno sobraba, volver a analizarlo repetiría el mismo error.
+			if n := len(p.errors); n == 0 || p.errors[n-1].Span != parseErr.Span {
+				p.errors = append(p.errors, parseErr)
+			}
+			// Garantizar avance si la sentencia falló en su primer token.
+			if p.current == start {
+				p.advance()
+			}
+			p.synchronize()
+			stmt = nil
+		}
+	}()
+	stmt = p.parseStatement()
+	p.consumeLineEnd("la sentencia")
+	return stmt
+}
+
+// synchronize descarta tokens hasta el inicio probable de otra sentencia:
+// tras un fin de línea o ';', o en una palabra clave de sentencia o de cierre.
+func (p *Parser) synchronize() {
+	for !p.isAtEnd() {
+		switch p.peek().Type {
+		case lexer.TOKEN_NEWLINE, lexer.TOKEN_SEMICOLON:
+			p.advance()
+			return
+		case lexer.TOKEN_IF, lexer.TOKEN_WHILE, lexer.TOKEN_FOR, lexer.TOKEN_FUNCTION,
+			lexer.TOKEN_RETURN, lexer.TOKEN_BREAK, lexer.TOKEN_CONTINUE, lexer.TOKEN_PRINT,
+			lexer.TOKEN_END, lexer.TOKEN_ELSE, lexer.TOKEN_ELSEIF:
+			return
+		}
+		p.advance()
+	}
+}
+
+// skipTerminators consume los fines de línea y ';' sobrantes entre sentencias.
+func (p *Parser) skipTerminators() {
+	for p.match(lexer.TOKEN_NEWLINE, lexer.TOKEN_SEMICOLON) {
+	}
+}
+
+// consumeLineEnd exige que what (una sentencia o la cabecera de un bloque)
+// termine con un fin de línea, un ';' o el fin de archivo.
+func (p *Parser) consumeLineEnd(what string) {
+	if p.match(lexer.TOKEN_NEWLINE, lexer.TOKEN_SEMICOLON) || p.isAtEnd() {
+		return
+	}
+	p.errorAt(p.peek(), fmt.Sprintf("Se esperaba fin de línea después de %s, se encontró %s", what, describe(p.peek())))
+}
+
+// atLineEnd indica si el token actual termina la sentencia.
+func (p *Parser) atLineEnd() bool {
+	return p.checkAny(lexer.TOKEN_NEWLINE, lexer.TOKEN_SEMICOLON, lexer.TOKEN_EOF)
 }
 
 func (p *Parser) parseStatement() ast.Statement {
+	if p.check(lexer.TOKEN_IDENTIFIER) && p.peekNext().Type == lexer.TOKEN_COLON {
+		return p.parseLabeled()
+	}
 	switch p.peek().Type {
 	case lexer.TOKEN_PRINT:
 		return p.parsePrint()
@@ -41,38 +121,186 @@ func (p *Parser) parseStatement() ast.Statement {
 	case lexer.TOKEN_FOR:
 		return p.parseFor()
 	case lexer.TOKEN_FUNCTION:
-		return p.parseFunction()
+		// 'function nombre(...)' declara; 'function(...)' es una expresión.
+		if p.peekNext().Type == lexer.TOKEN_IDENTIFIER {
+			return p.parseFunction()
+		}
+		expr := p.parseExpression()
+		return &ast.ExpressionStmt{Expr: expr, Span: expr.NodeSpan()}
 	case lexer.TOKEN_RETURN:
 		return p.parseReturn()
 	case lexer.TOKEN_BREAK:
-		p.advance()
-		return &ast.BreakStmt{}
+		tok := p.advance()
+		label := p.parseJumpLabel()
+		return &ast.BreakStmt{Label: label, Span: p.spanFrom(tok)}
 	case lexer.TOKEN_CONTINUE:
-		p.advance()
-		return &ast.ContinueStmt{}
+		tok := p.advance()
+		label := p.parseJumpLabel()
+		return &ast.ContinueStmt{Label: label, Span: p.spanFrom(tok)}
 	default:
-		if p.match(lexer.TOKEN_IDENTIFIER) && p.match(lexer.TOKEN_ASSIGN) {
-			name := p.previous(1).Lexeme
-			value := p.parseExpression()
-			return &ast.AssignmentStmt{Name: name, Value: value}
-		}
 		expr := p.parseExpression()
-		return &ast.ExpressionStmt{Expr: expr}
+		if p.match(assignOperators...) {
+			return p.finishAssignment(expr)
+		}
+		if call := p.parseStatementCall(expr); call != nil {
+			expr = call
+		}
+		return &ast.ExpressionStmt{Expr: expr, Span: expr.NodeSpan()}
+	}
+}
+
+// parseLabeled analiza un ciclo con etiqueta, 'nombre: while ...' o
+// 'nombre: for ...', al que 'break nombre' y 'continue nombre' pueden
+// referirse desde ciclos internos. El ciclo puede empezar en la línea siguiente.
+func (p *Parser) parseLabeled() ast.Statement {
+	labelTok := p.advance()
+	p.advance() // ':'
+	p.match(lexer.TOKEN_NEWLINE)
+	label := labelTok.Lexeme
+	switch p.peek().Type {
+	case lexer.TOKEN_WHILE:
+		stmt := p.parseWhile().(*ast.WhileStmt)
+		stmt.Label, stmt.Span = label, p.spanFrom(labelTok)
+		return stmt
+	case lexer.TOKEN_FOR:
+		switch stmt := p.parseFor().(type) {
+		case *ast.ForStmt:
+			stmt.Label, stmt.Span = label, p.spanFrom(labelTok)
+			return stmt
+		case *ast.ForInStmt:
+			stmt.Label, stmt.Span = label, p.spanFrom(labelTok)
+			return stmt
+		}
+	}
+	p.errorAt(p.peek(), fmt.Sprintf("Solo un ciclo puede llevar la etiqueta '%s', se encontró %s", label, describe(p.peek())))
+	return nil
+}
+
+// parseJumpLabel analiza la etiqueta opcional de 'break' o 'continue'.
+func (p *Parser) parseJumpLabel() string {
+	if p.check(lexer.TOKEN_IDENTIFIER) {
+		return p.advance().Lexeme
+	}
+	return ""
+}
+
+// assignOperators son '=' y los operadores de asignación compuesta.
+var assignOperators = []lexer.TokenType{
+	lexer.TOKEN_ASSIGN,
+	lexer.TOKEN_PLUS_ASSIGN,
+	lexer.TOKEN_MINUS_ASSIGN,
+	lexer.TOKEN_ASTERISK_ASSIGN,
+	lexer.TOKEN_SLASH_ASSIGN,
+	lexer.TOKEN_PERCENT_ASSIGN,
+	lexer.TOKEN_CARET_ASSIGN,
+}
+
+// finishAssignment construye la asignación a target una vez consumido el '='
+// o el operador compuesto. Solo variables, índices ('a[i] = v') y miembros
+// ('m.a = v') son destinos válidos.
+func (p *Parser) finishAssignment(target ast.Expression) ast.Statement {
+	assignTok := p.previous(0)
+	op := assignTok.Lexeme
+	value := p.parseExpression()
+	span := target.NodeSpan().Join(value.NodeSpan())
+	switch t := target.(type) {
+	case *ast.VariableExpr:
+		return &ast.AssignmentStmt{Name: t.Name, Operator: op, Value: value, Span: span}
+	case *ast.IndexExpr:
+		return &ast.IndexAssignStmt{Object: t.Object, Index: t.Index, Operator: op, Value: value, Span: span}
+	case *ast.MemberExpr:
+		return &ast.MemberAssignStmt{Object: t.Object, Name: t.Name, Operator: op, Value: value, Span: span}
+	default:
+		p.errorAt(assignTok, "Destino de asignación inválido")
+		return nil
+	}
+}
+
+// parseStatementCall reconoce la llamada sin paréntesis de MiniScript
+// ('foo 1, 2'): una variable o miembro seguido, en la misma sentencia, de
+// argumentos separados por comas. Devuelve nil si no es el caso.
+func (p *Parser) parseStatementCall(callee ast.Expression) ast.Expression {
+	switch callee.(type) {
+	case *ast.VariableExpr, *ast.MemberExpr:
+	default:
+		return nil
+	}
+	if !startsExpression(p.peek()) {
+		return nil
+	}
+	args := []ast.Expression{p.parseExpression()}
+	for p.match(lexer.TOKEN_COMMA) {
+		args = append(args, p.parseExpression())
+	}
+	return &ast.CallExpr{Callee: callee, Arguments: args, Span: callee.NodeSpan().Join(p.previous(0).Span)}
+}
+
+// startsExpression indica si tok puede iniciar el primer argumento de una
+// llamada sin paréntesis. Se excluye '-' porque 'a -1' es una resta.
+func startsExpression(tok lexer.Token) bool {
+	switch tok.Type {
+	case lexer.TOKEN_NUMBER, lexer.TOKEN_STRING, lexer.TOKEN_TEMPLATE, lexer.TOKEN_IDENTIFIER,
+		lexer.TOKEN_TRUE, lexer.TOKEN_FALSE, lexer.TOKEN_NIL,
+		lexer.TOKEN_LPAREN, lexer.TOKEN_LBRACE, lexer.TOKEN_NOT, lexer.TOKEN_NEW,
+		lexer.TOKEN_FUNCTION, lexer.TOKEN_AT:
+		return true
 	}
+	return false
 }
 
 // parseExpression inicia el análisis de expresiones.
 func (p *Parser) parseExpression() ast.Expression {
-	return p.parseEquality()
+	return p.parseOr()
+}
+
+// Precedencia: or -> and -> not -> isa -> equality -> comparison -> term -> factor -> unary -> power -> call -> primary
+func (p *Parser) parseOr() ast.Expression {
+	expr := p.parseAnd()
+	for p.match(lexer.TOKEN_OR) {
+		op := p.previous(0).Lexeme
+		right := p.parseAnd()
+		expr = &ast.LogicalExpr{Left: expr, Operator: op, Right: right, Span: expr.NodeSpan().Join(right.NodeSpan())}
+	}
+	return expr
+}
+
+func (p *Parser) parseAnd() ast.Expression {
+	expr := p.parseNot()
+	for p.match(lexer.TOKEN_AND) {
+		op := p.previous(0).Lexeme
+		right := p.parseNot()
+		expr = &ast.LogicalExpr{Left: expr, Operator: op, Right: right, Span: expr.NodeSpan().Join(right.NodeSpan())}
+	}
+	return expr
+}
+
+// parseNot tiene menor precedencia que las comparaciones: 'not a == b' es 'not (a == b)'.
+func (p *Parser) parseNot() ast.Expression {
+	if p.match(lexer.TOKEN_NOT) {
+		opTok := p.previous(0)
+		right := p.parseNot()
+		return &ast.UnaryExpr{Operator: opTok.Lexeme, Right: right, Span: opTok.Span.Join(right.NodeSpan())}
+	}
+	return p.parseIsa()
+}
+
+// parseIsa analiza la comprobación de tipo 'obj isa Clase'.
+func (p *Parser) parseIsa() ast.Expression {
+	expr := p.parseEquality()
+	for p.match(lexer.TOKEN_ISA) {
+		op := p.previous(0).Lexeme
+		right := p.parseEquality()
+		expr = &ast.BinaryExpr{Left: expr, Operator: op, Right: right, Span: expr.NodeSpan().Join(right.NodeSpan())}
+	}
+	return expr
 }
 
-// Precedencia: equality -> comparison -> term -> factor -> unary -> primary
 func (p *Parser) parseEquality() ast.Expression {
 	expr := p.parseComparison()
 	for p.match(lexer.TOKEN_EQ, lexer.TOKEN_NEQ) {
 		op := p.previous(0).Lexeme
 		right := p.parseComparison()
-		expr = &ast.BinaryExpr{Left: expr, Operator: op, Right: right}
+		expr = &ast.BinaryExpr{Left: expr, Operator: op, Right: right, Span: expr.NodeSpan().Join(right.NodeSpan())}
 	}
 	return expr
 }
@@ -82,7 +310,7 @@ func (p *Parser) parseComparison() ast.Expression {
 	for p.match(lexer.TOKEN_GT, lexer.TOKEN_GTE, lexer.TOKEN_LT, lexer.TOKEN_LTE) {
 		op := p.previous(0).Lexeme
 		right := p.parseTerm()
-		expr = &ast.BinaryExpr{Left: expr, Operator: op, Right: right}
+		expr = &ast.BinaryExpr{Left: expr, Operator: op, Right: right, Span: expr.NodeSpan().Join(right.NodeSpan())}
 	}
 	return expr
 }
@@ -92,28 +320,104 @@ func (p *Parser) parseTerm() ast.Expression {
 	for p.match(lexer.TOKEN_PLUS, lexer.TOKEN_MINUS) {
 		op := p.previous(0).Lexeme
 		right := p.parseFactor()
-		expr = &ast.BinaryExpr{Left: expr, Operator: op, Right: right}
+		expr = &ast.BinaryExpr{Left: expr, Operator: op, Right: right, Span: expr.NodeSpan().Join(right.NodeSpan())}
 	}
 	return expr
 }
 
 func (p *Parser) parseFactor() ast.Expression {
 	expr := p.parseUnary()
-	for p.match(lexer.TOKEN_SLASH, lexer.TOKEN_ASTERISK, lexer.TOKEN_PERCENT, lexer.TOKEN_CARET) {
+	for p.match(lexer.TOKEN_SLASH, lexer.TOKEN_ASTERISK, lexer.TOKEN_PERCENT) {
 		op := p.previous(0).Lexeme
 		right := p.parseUnary()
-		expr = &ast.BinaryExpr{Left: expr, Operator: op, Right: right}
+		expr = &ast.BinaryExpr{Left: expr, Operator: op, Right: right, Span: expr.NodeSpan().Join(right.NodeSpan())}
 	}
 	return expr
 }
 
 func (p *Parser) parseUnary() ast.Expression {
-	if p.match(lexer.TOKEN_NOT, lexer.TOKEN_MINUS) {
-		op := p.previous(0).Lexeme
+	if p.match(lexer.TOKEN_MINUS, lexer.TOKEN_NEW) {
+		opTok := p.previous(0)
 		right := p.parseUnary()
-		return &ast.UnaryExpr{Operator: op, Right: right}
+		return &ast.UnaryExpr{Operator: opTok.Lexeme, Right: right, Span: opTok.Span.Join(right.NodeSpan())}
+	}
+	return p.parsePower()
+}
+
+// parsePower analiza '^', que liga más fuerte que el menos unario y asocia
+// a la derecha: '-2^2' es -(2^2) y '2^3^2' es 2^(3^2). El exponente pasa por
+// parseUnary para admitir '2^-1'.
+func (p *Parser) parsePower() ast.Expression {
+	base := p.parseAddressOf()
+	if p.match(lexer.TOKEN_CARET) {
+		op := p.previous(0).Lexeme
+		exponent := p.parseUnary()
+		return &ast.BinaryExpr{Left: base, Operator: op, Right: exponent, Span: base.NodeSpan().Join(exponent.NodeSpan())}
+	}
+	return base
+}
+
+// parseAddressOf analiza '@f', que obtiene una función sin invocarla.
+func (p *Parser) parseAddressOf() ast.Expression {
+	if p.match(lexer.TOKEN_AT) {
+		atTok := p.previous(0)
+		operand := p.parseCall()
+		return &ast.AddressOfExpr{Expr: operand, Span: atTok.Span.Join(operand.NodeSpan())}
+	}
+	return p.parseCall()
+}
+
+// parseCall analiza los sufijos de una expresión primaria: llamadas
+// 'f(a, b)', llamadas encadenadas 'f(1)(2)' y acceso a miembros 'obj.nombre'.
+// Un '(' en la línea siguiente queda separado por un TOKEN_NEWLINE, así que
+// inicia una nueva sentencia.
+func (p *Parser) parseCall() ast.Expression {
+	expr := p.parsePrimary()
+	for {
+		switch {
+		case p.match(lexer.TOKEN_LPAREN):
+			expr = p.finishCall(expr)
+		case p.match(lexer.TOKEN_LBRACKET):
+			expr = p.finishIndex(expr)
+		case p.match(lexer.TOKEN_DOT):
+			name := p.consume(lexer.TOKEN_IDENTIFIER, "Se esperaba nombre de miembro después de '.'")
+			expr = &ast.MemberExpr{Object: expr, Name: name.Lexeme, Span: expr.NodeSpan().Join(name.Span)}
+		default:
+			return expr
+		}
+	}
+}
+
+// finishIndex analiza un índice 'a[i]' o un corte 'a[i:j]' ya abierto con '['.
+// En un corte ambos extremos son opcionales: 'a[:j]', 'a[i:]'.
+func (p *Parser) finishIndex(object ast.Expression) ast.Expression {
+	var start ast.Expression
+	if !p.check(lexer.TOKEN_COLON) {
+		start = p.parseExpression()
+	}
+	if !p.match(lexer.TOKEN_COLON) {
+		p.consume(lexer.TOKEN_RBRACKET, "Se esperaba ']' después del índice")
+		return &ast.IndexExpr{Object: object, Index: start, Span: object.NodeSpan().Join(p.previous(0).Span)}
+	}
+	var end ast.Expression
+	if !p.check(lexer.TOKEN_RBRACKET) {
+		end = p.parseExpression()
 	}
-	return p.parsePrimary()
+	p.consume(lexer.TOKEN_RBRACKET, "Se esperaba ']' después del corte")
+	return &ast.SliceExpr{Object: object, Start: start, End: end, Span: object.NodeSpan().Join(p.previous(0).Span)}
+}
+
+// finishCall analiza los argumentos de una llamada ya abierta con '('.
+func (p *Parser) finishCall(callee ast.Expression) ast.Expression {
+	var args []ast.Expression
+	if !p.check(lexer.TOKEN_RPAREN) {
+		args = append(args, p.parseExpression())
+		for p.match(lexer.TOKEN_COMMA) {
+			args = append(args, p.parseExpression())
+		}
+	}
+	p.consume(lexer.TOKEN_RPAREN, "Se esperaba ')' después de los argumentos")
+	return &ast.CallExpr{Callee: callee, Arguments: args, Span: callee.NodeSpan().Join(p.previous(0).Span)}
 }
 
 func (p *Parser) parsePrimary() ast.Expression {
@@ -121,47 +425,120 @@ func (p *Parser) parsePrimary() ast.Expression {
 	switch tok.Type {
 	case lexer.TOKEN_FALSE:
 		p.advance()
-		return &ast.LiteralExpr{Value: false}
+		return &ast.LiteralExpr{Value: false, Span: tok.Span}
 	case lexer.TOKEN_TRUE:
 		p.advance()
-		return &ast.LiteralExpr{Value: true}
+		return &ast.LiteralExpr{Value: true, Span: tok.Span}
 	case lexer.TOKEN_NIL:
 		p.advance()
-		return &ast.LiteralExpr{Value: nil}
+		return &ast.LiteralExpr{Value: nil, Span: tok.Span}
 	case lexer.TOKEN_NUMBER, lexer.TOKEN_STRING:
 		p.advance()
-		return &ast.LiteralExpr{Value: tok.Literal}
+		return &ast.LiteralExpr{Value: tok.Literal, Span: tok.Span}
+	case lexer.TOKEN_TEMPLATE:
+		p.advance()
+		return p.parseTemplate(tok)
 	case lexer.TOKEN_IDENTIFIER:
 		p.advance()
-		return &ast.VariableExpr{Name: tok.Lexeme}
+		return &ast.VariableExpr{Name: tok.Lexeme, Span: tok.Span}
 	case lexer.TOKEN_LPAREN:
 		p.advance()
 		expr := p.parseExpression()
 		p.consume(lexer.TOKEN_RPAREN, "Se esperaba ')' después de la expresión")
-		return &ast.GroupingExpr{Expression: expr}
+		return &ast.GroupingExpr{Expression: expr, Span: p.spanFrom(tok)}
+	case lexer.TOKEN_LBRACKET:
+		p.advance()
+		return p.parseList(tok)
+	case lexer.TOKEN_LBRACE:
+		p.advance()
+		return p.parseMap(tok)
+	case lexer.TOKEN_FUNCTION:
+		p.advance()
+		return p.parseFunctionExpr(tok)
 	default:
-		panic(fmt.Sprintf("Token inesperado en expresión: %v", tok))
+		p.errorAt(tok, fmt.Sprintf("Token inesperado en expresión: %s", describe(tok)))
+		return nil
+	}
+}
+
+// parseTemplate convierte un TOKEN_TEMPLATE en un InterpolatedStringExpr.
+// Cada '{...}' trae sus propios tokens y se analiza con un parser aparte.
+func (p *Parser) parseTemplate(tok lexer.Token) ast.Expression {
+	var parts []ast.Expression
+	for _, part := range tok.Literal.([]lexer.TemplatePart) {
+		if part.Tokens == nil {
+			parts = append(parts, &ast.LiteralExpr{Value: part.Text, Span: tok.Span})
+			continue
+		}
+		sub := New(part.Tokens)
+		expr := sub.parseExpression()
+		if !sub.isAtEnd() {
+			sub.errorAt(sub.peek(), fmt.Sprintf("Se esperaba '}' al cerrar la interpolación, se encontró %s", describe(sub.peek())))
+		}
+		p.errors = append(p.errors, sub.errors...)
+		parts = append(parts, expr)
+	}
+	return &ast.InterpolatedStringExpr{Parts: parts, Span: tok.Span}
+}
+
+// parseList analiza un literal de lista '[a, b, c]'; se admite una coma final.
+func (p *Parser) parseList(open lexer.Token) ast.Expression {
+	var elems []ast.Expression
+	for !p.check(lexer.TOKEN_RBRACKET) {
+		elems = append(elems, p.parseExpression())
+		if !p.match(lexer.TOKEN_COMMA) {
+			break
+		}
+	}
+	p.consume(lexer.TOKEN_RBRACKET, "Se esperaba ']' al cerrar la lista")
+	return &ast.ListExpr{Elements: elems, Span: p.spanFrom(open)}
+}
+
+// parseMap analiza un literal de mapa '{clave: valor, ...}'; se admite una coma final.
+func (p *Parser) parseMap(open lexer.Token) ast.Expression {
+	var keys, values []ast.Expression
+	for !p.check(lexer.TOKEN_RBRACE) {
+		key := p.parseExpression()
+		p.consume(lexer.TOKEN_COLON, "Se esperaba ':' después de la clave")
+		keys = append(keys, key)
+		values = append(values, p.parseExpression())
+		if !p.match(lexer.TOKEN_COMMA) {
+			break
+		}
 	}
+	p.consume(lexer.TOKEN_RBRACE, "Se esperaba '}' al cerrar el mapa")
+	return &ast.MapExpr{Keys: keys, Values: values, Span: p.spanFrom(open)}
 }
 
+// parseIf analiza un if en bloque, con 'then' opcional y cerrado por 'end if',
+// o en una sola línea: 'if c then sentencia else sentencia'. Ambas formas
+// producen el mismo IfStmt.
 func (p *Parser) parseIf() ast.Statement {
-	p.advance() // consumir 'if'
+	ifTok := p.advance() // consumir 'if'
 	cond := p.parseExpression()
-	thenBlock := p.parseBlock()
+	if p.match(lexer.TOKEN_THEN) && !p.atLineEnd() {
+		return p.finishSingleLineIf(ifTok, cond)
+	}
+	p.consumeLineEnd("la condición")
+	thenBlock := p.parseBlock(lexer.TOKEN_ELSEIF, lexer.TOKEN_ELSE)
 
 	var elseifConds []ast.Expression
 	var elseifBodies [][]ast.Statement
 	for p.match(lexer.TOKEN_ELSEIF) {
 		elifCond := p.parseExpression()
-		elifBody := p.parseBlock()
+		p.match(lexer.TOKEN_THEN)
+		p.consumeLineEnd("la condición")
+		elifBody := p.parseBlock(lexer.TOKEN_ELSEIF, lexer.TOKEN_ELSE)
 		elseifConds = append(elseifConds, elifCond)
 		elseifBodies = append(elseifBodies, elifBody)
 	}
 
 	var elseBlock []ast.Statement
 	if p.match(lexer.TOKEN_ELSE) {
+		p.consumeLineEnd("'else'")
 		elseBlock = p.parseBlock()
 	}
+	p.parseEnd(ifTok)
 
 	return &ast.IfStmt{
 		Condition:   cond,
@@ -169,65 +546,177 @@ func (p *Parser) parseIf() ast.Statement {
 		ElseIfConds: elseifConds,
 		ElseIfBods:  elseifBodies,
 		ElseBlock:   elseBlock,
+		Span:        p.spanFrom(ifTok),
 	}
 }
 
-func (p *Parser) parseBlock() []ast.Statement {
+// finishSingleLineIf analiza el resto de un if de una línea, ya consumido el
+// 'then'. Cada rama es una sola sentencia; la del else puede ser otro if.
+func (p *Parser) finishSingleLineIf(ifTok lexer.Token, cond ast.Expression) ast.Statement {
+	stmt := &ast.IfStmt{Condition: cond, ThenBlock: []ast.Statement{p.parseStatement()}}
+	if p.match(lexer.TOKEN_ELSE) {
+		stmt.ElseBlock = []ast.Statement{p.parseStatement()}
+	}
+	stmt.Span = p.spanFrom(ifTok)
+	return stmt
+}
+
+// parseBlock analiza sentencias hasta encontrar 'end' o alguno de los tokens
+// de stop (por ejemplo 'else' dentro de un if). No consume el token final.
+func (p *Parser) parseBlock(stop ...lexer.TokenType) []ast.Statement {
 	var stmts []ast.Statement
-	for !p.check(lexer.TOKEN_END) && !p.isAtEnd() {
-		st := p.parseStatement()
+	for p.skipTerminators(); !p.check(lexer.TOKEN_END) && !p.isAtEnd() && !p.checkAny(stop...); p.skipTerminators() {
+		st := p.parseStatementRecover()
 		if st != nil {
 			stmts = append(stmts, st)
 		}
 	}
-	p.consume(lexer.TOKEN_END, "Se esperaba 'end' al cerrar bloque")
 	return stmts
 }
 
-func (p *Parser) parsePrint() ast.Statement {
+// blockKeywords son las palabras que pueden acompañar a 'end' para cerrar un bloque.
+var blockKeywords = map[lexer.TokenType]bool{
+	lexer.TOKEN_IF:       true,
+	lexer.TOKEN_WHILE:    true,
+	lexer.TOKEN_FOR:      true,
+	lexer.TOKEN_FUNCTION: true,
+}
+
+// parseEnd consume el cierre del bloque abierto por opener: un 'end' solo o
+// seguido de la palabra del bloque ('end while', 'end if'...).
+// Si la palabra no coincide con opener se reporta el error y se sigue analizando.
+func (p *Parser) parseEnd(opener lexer.Token) {
+	p.consume(lexer.TOKEN_END, fmt.Sprintf("Se esperaba 'end %s' al cerrar bloque", opener.Lexeme))
+	kind := p.peek()
+	if !blockKeywords[kind.Type] {
+		return
+	}
 	p.advance()
+	if kind.Type != opener.Type {
+		p.report(kind, fmt.Sprintf("'end %s' no coincide con '%s' abierto en línea %d, columna %d",
+			kind.Lexeme, opener.Lexeme, opener.Line, opener.Column))
+	}
+}
+
+func (p *Parser) parsePrint() ast.Statement {
+	printTok := p.advance()
 	value := p.parseExpression()
-	return &ast.PrintStmt{Value: value}
+	return &ast.PrintStmt{Value: value, Span: p.spanFrom(printTok)}
 }
 
 func (p *Parser) parseWhile() ast.Statement {
-	p.advance()
+	whileTok := p.advance()
 	cond := p.parseExpression()
+	p.consumeLineEnd("la condición")
 	body := p.parseBlock()
-	return &ast.WhileStmt{Condition: cond, Body: body}
+	p.parseEnd(whileTok)
+	return &ast.WhileStmt{Condition: cond, Body: body, Span: p.spanFrom(whileTok)}
 }
 
+// parseFor analiza el for numérico 'for i = a to b [step c]' ('range'
+// equivale a 'to') y el for sobre colecciones 'for x in coleccion'. 'range'
+// y 'step' no son palabras reservadas: 'range' también nombra a la función
+// intrínseca y 'step' puede seguir usándose como variable.
 func (p *Parser) parseFor() ast.Statement {
-	p.advance()
+	forTok := p.advance()
 	name := p.consume(lexer.TOKEN_IDENTIFIER, "Se esperaba identificador en for").Lexeme
-	p.consume(lexer.TOKEN_ASSIGN, "Se esperaba '=' en for")
+	if p.match(lexer.TOKEN_IN) {
+		iterable := p.parseExpression()
+		p.consumeLineEnd("la cabecera del for")
+		body := p.parseBlock()
+		p.parseEnd(forTok)
+		return &ast.ForInStmt{VarName: name, Iterable: iterable, Body: body, Span: p.spanFrom(forTok)}
+	}
+	p.consume(lexer.TOKEN_ASSIGN, "Se esperaba '=' o 'in' en for")
 	start := p.parseExpression()
-	p.consume(lexer.TOKEN_RANGE, "Se esperaba 'range/to' en for")
+	if !p.match(lexer.TOKEN_TO) && !p.matchWord("range") {
+		p.errorAt(p.peek(), fmt.Sprintf("Se esperaba 'to' o 'range' en for, se encontró %s", describe(p.peek())))
+	}
 	end := p.parseExpression()
+	var step ast.Expression
+	if p.matchWord("step") {
+		step = p.parseExpression()
+	}
+	p.consumeLineEnd("la cabecera del for")
 	body := p.parseBlock()
-	return &ast.ForStmt{VarName: name, StartExpr: start, EndExpr: end, Body: body}
+	p.parseEnd(forTok)
+	return &ast.ForStmt{VarName: name, StartExpr: start, EndExpr: end, StepExpr: step, Body: body, Span: p.spanFrom(forTok)}
 }
 
 func (p *Parser) parseFunction() ast.Statement {
-	p.advance()
+	funcTok := p.advance()
 	name := p.consume(lexer.TOKEN_IDENTIFIER, "Se esperaba nombre de función").Lexeme
+	params := p.parseParameters()
+	p.consumeLineEnd("los parámetros")
+	body := p.parseBlock()
+	p.parseEnd(funcTok)
+	return &ast.FunctionStmt{Name: name, Doc: docComment(funcTok), Parameters: params, Body: body, Span: p.spanFrom(funcTok)}
+}
+
+// docComment reúne los comentarios '///' que preceden a tok, una línea por
+// comentario y sin el prefijo '///' ni el espacio que lo sigue.
+func docComment(tok lexer.Token) string {
+	var lines []string
+	for _, trivia := range tok.LeadingTrivia {
+		if trivia.Kind != lexer.TRIVIA_DOC_COMMENT {
+			continue
+		}
+		line := strings.TrimSuffix(strings.TrimPrefix(trivia.Text, "///"), "\r")
+		lines = append(lines, strings.TrimPrefix(line, " "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseFunctionExpr analiza una función anónima 'function(a, b) ... end function'.
+func (p *Parser) parseFunctionExpr(funcTok lexer.Token) ast.Expression {
+	params := p.parseParameters()
+	p.consumeLineEnd("los parámetros")
+	body := p.parseBlock()
+	p.parseEnd(funcTok)
+	return &ast.FunctionExpr{Parameters: params, Body: body, Span: p.spanFrom(funcTok)}
+}
+
+// parseParameters analiza la lista de parámetros '(a, b = 10, resto...)'.
+// Los parámetros con valor por defecto van después de los obligatorios y el
+// variádico, si existe, es el último.
+func (p *Parser) parseParameters() []*ast.Parameter {
 	p.consume(lexer.TOKEN_LPAREN, "Se esperaba '('")
-	var params []string
-	if !p.check(lexer.TOKEN_RPAREN) {
-		params = append(params, p.consume(lexer.TOKEN_IDENTIFIER, "Se esperaba parámetro").Lexeme)
-		for p.match(lexer.TOKEN_COMMA) {
-			params = append(params, p.consume(lexer.TOKEN_IDENTIFIER, "Se esperaba parámetro").Lexeme)
+	var params []*ast.Parameter
+	seenDefault := false
+	for !p.check(lexer.TOKEN_RPAREN) {
+		if len(params) > 0 && params[len(params)-1].Variadic {
+			p.errorAt(p.peek(), "El parámetro variádico debe ser el último")
+		}
+		nameTok := p.consume(lexer.TOKEN_IDENTIFIER, "Se esperaba parámetro")
+		param := &ast.Parameter{Name: nameTok.Lexeme}
+		switch {
+		case p.match(lexer.TOKEN_ASSIGN):
+			param.Default = p.parseExpression()
+			seenDefault = true
+		case p.match(lexer.TOKEN_ELLIPSIS):
+			param.Variadic = true
+		case seenDefault:
+			p.errorAt(nameTok, fmt.Sprintf("El parámetro '%s' necesita un valor por defecto porque sigue a uno que lo tiene", nameTok.Lexeme))
+		}
+		param.Span = p.spanFrom(nameTok)
+		params = append(params, param)
+		if !p.match(lexer.TOKEN_COMMA) {
+			break
 		}
 	}
 	p.consume(lexer.TOKEN_RPAREN, "Se esperaba ')'")
-	body := p.parseBlock()
-	return &ast.FunctionStmt{Name: name, Parameters: params, Body: body}
+	return params
 }
 
+// parseReturn analiza 'return valor' o un 'return' solo, que devuelve nil.
 func (p *Parser) parseReturn() ast.Statement {
-	p.advance()
-	val := p.parseExpression()
-	return &ast.ReturnStmt{Value: val}
+	returnTok := p.advance()
+	var val ast.Expression
+	// En un if de una línea el 'return' puede ir seguido de 'else'.
+	if !p.atLineEnd() && !p.check(lexer.TOKEN_ELSE) {
+		val = p.parseExpression()
+	}
+	return &ast.ReturnStmt{Value: val, Span: p.spanFrom(returnTok)}
 }
 
 // Métodos auxiliares
@@ -245,13 +734,72 @@ func (p *Parser) consume(t lexer.TokenType, msg string) lexer.Token {
 	if p.peek().Type == t {
 		return p.advance()
 	}
-	panic(msg)
+	p.errorAt(p.peek(), fmt.Sprintf("%s, se encontró %s", msg, describe(p.peek())))
+	return lexer.Token{}
+}
+
+// spanFrom devuelve el rango desde start hasta el último token consumido.
+func (p *Parser) spanFrom(start lexer.Token) lexer.Span {
+	return start.Span.Join(p.previous(0).Span)
+}
+
+// errorAt aborta la sentencia actual con un ParseError ubicado en tok;
+// parseStatementRecover lo registra y reanuda el análisis.
+func (p *Parser) errorAt(tok lexer.Token, msg string) {
+	panic(&ParseError{
+		Message: msg,
+		Line:    tok.Line,
+		Column:  tok.Column,
+		Span:    tok.Span,
+	})
+}
+
+// report registra un ParseError en tok sin abortar la sentencia actual.
+func (p *Parser) report(tok lexer.Token, msg string) {
+	p.errors = append(p.errors, &ParseError{
+		Message: msg,
+		Line:    tok.Line,
+		Column:  tok.Column,
+		Span:    tok.Span,
+	})
+}
+
+// describe devuelve una descripción legible de tok para los mensajes de error.
+func describe(tok lexer.Token) string {
+	// El EOF de una interpolación conserva el lexema '}' que la cierra.
+	if tok.Type == lexer.TOKEN_EOF && tok.Lexeme == "" {
+		return "fin de archivo"
+	}
+	if tok.Type == lexer.TOKEN_NEWLINE {
+		return "fin de línea"
+	}
+	return fmt.Sprintf("'%s'", tok.Lexeme)
+}
+
+// matchWord consume el token actual si es el identificador word, usado
+// como palabra clave contextual.
+func (p *Parser) matchWord(word string) bool {
+	if p.check(lexer.TOKEN_IDENTIFIER) && p.peek().Lexeme == word {
+		p.advance()
+		return true
+	}
+	return false
 }
 
 func (p *Parser) check(t lexer.TokenType) bool {
 	return p.peek().Type == t
 }
 
+// checkAny indica si el token actual es de alguno de los tipos dados.
+func (p *Parser) checkAny(types ...lexer.TokenType) bool {
+	for _, t := range types {
+		if p.check(t) {
+			return true
+		}
+	}
+	return false
+}
+
 func (p *Parser) advance() lexer.Token {
 	if !p.isAtEnd() {
 		p.current++
@@ -259,14 +807,23 @@ func (p *Parser) advance() lexer.Token {
 	return p.tokens[p.current-1]
 }
 
+// previous devuelve un token ya consumido; previous(0) es el último.
 func (p *Parser) previous(offset int) lexer.Token {
-	return p.tokens[p.current-offset]
+	return p.tokens[p.current-1-offset]
 }
 
 func (p *Parser) peek() lexer.Token {
 	return p.tokens[p.current]
 }
 
+// peekNext devuelve el token siguiente al actual sin consumirlo.
+func (p *Parser) peekNext() lexer.Token {
+	if p.current+1 >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.current+1]
+}
+
 func (p *Parser) isAtEnd() bool {
 	return p.peek().Type == lexer.TOKEN_EOF
 }
@@ -0,0 +1,384 @@
+package test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DAlfaroV/miniscript/internal/interpreter"
+	"github.com/DAlfaroV/miniscript/internal/lexer"
+	"github.com/DAlfaroV/miniscript/internal/parser"
+	"github.com/DAlfaroV/miniscript/internal/parser/ast"
+)
+
+// runSource lexea, parsea y ejecuta src, devolviendo lo impreso.
+func runSource(t *testing.T, src string) string {
+	t.Helper()
+	tokens, err := lexer.NewLexer(src).ScanTokens()
+	if err != nil {
+		t.Fatalf("Error léxico: %v", err)
+	}
+	prog, err := parser.New(tokens).ParseProgram()
+	if err != nil {
+		t.Fatalf("Error sintáctico: %v", err)
+	}
+	return runProgram(t, prog)
+}
+
+func runProgram(t *testing.T, prog *ast.Program) string {
+	t.Helper()
+	var out bytes.Buffer
+	if err := interpreter.New(&out).Run(prog); err != nil {
+		t.Fatalf("Error de ejecución: %v", err)
+	}
+	return out.String()
+}
+
+// runError lexea, parsea y ejecuta src, que debe fallar durante la ejecución,
+// y devuelve el error.
+func runError(t *testing.T, src string) *interpreter.RuntimeError {
+	t.Helper()
+	tokens, err := lexer.NewLexer(src).ScanTokens()
+	if err != nil {
+		t.Fatalf("Error léxico: %v", err)
+	}
+	prog, err := parser.New(tokens).ParseProgram()
+	if err != nil {
+		t.Fatalf("Error sintáctico: %v", err)
+	}
+	err = interpreter.New(&bytes.Buffer{}).Run(prog)
+	rtErr, ok := err.(*interpreter.RuntimeError)
+	if !ok {
+		t.Fatalf("se esperaba *RuntimeError para %q, se obtuvo %v", src, err)
+	}
+	return rtErr
+}
+
+func TestInterpreterPrograms(t *testing.T) {
+	cases := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"aritmética", `print 1 + 2 * 3`, "7\n"},
+		{"decimales", `print 10 / 4`, "2.5\n"},
+		{"concatenación", `x = 3; print "x = " + x`, "x = 3\n"},
+		{"while", "n = 0\nwhile n < 3\nprint n; n = n + 1\nend", "0\n1\n2\n"},
+		{"if elseif else", "x = 5\nif x > 10\nprint \"a\"\nelseif x > 3\nprint \"b\"\nelse\nprint \"c\"\nend if", "b\n"},
+		{"for", "for i = 1 to 3\nprint i\nend for", "1\n2\n3\n"},
+		{"for con range", "for i = 1 range 2\nprint i\nend", "1\n2\n"},
+		{"break y continue", "for i = 1 to 5\nif i == 2\ncontinue\nend if\nif i == 4\nbreak\nend if\nprint i\nend for", "1\n3\n"},
+		{"comparación de cadenas", `print "a" < "b"`, "true\n"},
+		{"potencia sobre producto", `print 2 * 3 ^ 2`, "18\n"},
+		{"potencia asocia a la derecha", `print 2 ^ 3 ^ 2`, "512\n"},
+		{"menos unario y potencia", `print -2 ^ 2`, "-4\n"},
+		{"exponente negativo", `print 2 ^ -1`, "0.5\n"},
+		{"potencia con agrupación", `print (-2) ^ 2`, "4\n"},
+		{"potencia y resta", `print 10 - 2 ^ 3`, "2\n"},
+		{"and", `print 1 < 2 and 2 < 3`, "true\n"},
+		{"or", `print 0 or ""`, "false\n"},
+		{"not", `print not 1 == 2`, "true\n"},
+		{"precedencia and/or", `print true or false and false`, "true\n"},
+		{"cortocircuito and", `print false and noDefinida`, "false\n"},
+		{"cortocircuito or", `print 1 or noDefinida`, "true\n"},
+		{"llamadas", "function suma(a, b)\nreturn a + b\nend function\nprint suma(1, suma(2, 3))", "6\n"},
+		{"llamada encadenada", "function sumador(a)\nfunction interna(b)\nreturn a + b\nend function\nreturn @interna\nend function\nprint sumador(1)(2)", "3\n"},
+		{"lista literal", `print [1, "dos", [3]]`, "[1, \"dos\", [3]]\n"},
+		{"índices", "a = [10, 20, 30]\nprint a[0] + a[-1]", "40\n"},
+		{"cortes", "a = [1, 2, 3, 4]\nprint a[1:3]\nprint a[:-1]\nprint a[2:]", "[2, 3]\n[1, 2, 3]\n[3, 4]\n"},
+		{"cortes de cadenas", "s = \"Iteración\"\nprint s[-3:]\nprint s[0]", "ión\nI\n"},
+		{"asignación por índice", "a = [1, 2]\na[-1] = 5\nprint a", "[1, 5]\n"},
+		{"referencia compartida", "a = [1]\nb = a\nb[0] = 9\nprint a", "[9]\n"},
+		{"concatenación y réplica", "print [1] + [2]\nprint [0] * 3", "[1, 2]\n[0, 0, 0]\n"},
+		{"igualdad de listas", `print [1, [2]] == [1, [2]]`, "true\n"},
+		{"lista que se contiene", "a = [1]\na[0] = a\nprint a\nprint [a, a]", "[[...]]\n[[[...]], [[...]]]\n"},
+		{"igualdad de listas cíclicas", "a = [1]\na[0] = a\nb = [1]\nb[0] = b\nprint a == a\nprint a == b", "true\nfalse\n"},
+		{"mapa literal", `print {"a": 1, "b": [2]}`, "{\"a\": 1, \"b\": [2]}\n"},
+		{"acceso a mapas", "m = {\"a\": 1, 2: \"dos\"}\nprint m[\"a\"] + m.a\nprint m[2]", "2\ndos\n"},
+		{"asignación de miembros", "m = {}\nm.a = 3\nm[\"b\"] = m.a + 1\nprint m", "{\"a\": 3, \"b\": 4}\n"},
+		{"mapas anidados", "cfg = {\"db\": {\"port\": 5432}}\ncfg.db.port = 6543\nprint cfg.db.port", "6543\n"},
+		{"igualdad de mapas", `print {"a": 1, "b": 2} == {"b": 2, "a": 1}`, "true\n"},
+		{"mapa que se contiene", "m = {}\nm.self = m\nprint m\nprint m == m\nn = {}\nn.self = n\nprint m == n", "{\"self\": {...}}\ntrue\nfalse\n"},
+		{"cadena de __isa cíclica", "a = {}\nb = new a\na.__isa = b\nprint a", "{\"__isa\": {\"__isa\": {...}}}\n"},
+		{"llamada sin paréntesis", "function saluda(nombre, n)\nprint nombre + n\nend function\nsaluda \"hola\", 1", "hola1\n"},
+		{"if de una línea", "x = -1\nif x > 0 then print \"pos\" else print \"neg\"\nif x < 0 then print \"menor\"", "neg\nmenor\n"},
+		{"if con then en bloque", "x = 2\nif x == 1 then\nprint 1\nelseif x == 2 then\nprint 2\nend if", "2\n"},
+		{"return en if de una línea", "function f(n)\nif n > 0 then return \"pos\" else return\nend function\nprint f(1)\nprint f(0)", "pos\nnil\n"},
+		{"for con step", "for i = 1 to 10 step 3\nprint i\nend for", "1\n4\n7\n10\n"},
+		{"for descendente", "for i = 3 to 1 step -1\nprint i\nend for", "3\n2\n1\n"},
+		{"for descendente sin step", "for i = 3 to 1\nprint i\nend for\nprint \"fin\"", "fin\n"},
+		{"for con step decimal", "n = 0\nfor x = 0 to 1 step 0.1\nn += 1\nend for\nprint n\nprint x", "11\n1\n"},
+		{"for con step en dirección contraria", "for i = 1 to 5 step -1\nprint i\nend for\nprint \"fin\"", "fin\n"},
+		{"step como variable", "step = 2\nfor i = 0 to 4 step step\nprint i\nend for", "0\n2\n4\n"},
+		{"for in sobre lista", "total = 0\nfor x in [1, 2, 3]\ntotal += x\nend for\nprint total", "6\n"},
+		{"for in sobre cadena", "for ch in \"año\"\nprint ch\nend for", "a\nñ\no\n"},
+		{"for in sobre mapa", "for kv in {\"a\": 1, \"b\": 2}\nprint kv.key + \"=\" + kv.value\nend for", "a=1\nb=2\n"},
+		{"for in con break", "for x in range(1, 10)\nif x > 2 then break\nprint x\nend for", "1\n2\n"},
+		{"range", "print range(3)\nprint range(1, 3)\nprint range(0, 1, 0.25)\nprint range(10, 0, -4)\nprint range(0, 3, -1)", "[3, 2, 1, 0]\n[1, 2, 3]\n[0, 0.25, 0.5, 0.75, 1]\n[10, 6, 2]\n[]\n"},
+		{"range sin deriva", "print range(0, 0.3, 0.1)", "[0, 0.1, 0.2, 0.3]\n"},
+		{"break con etiqueta", "outer: for i = 1 to 3\nfor j = 1 to 3\nif i * j == 4 then break outer\nprint i * 10 + j\nend for\nend for", "11\n12\n13\n21\n"},
+		{"continue con etiqueta", "outer: for x in [1, 2]\nwhile true\nprint x\ncontinue outer\nend while\nend for", "1\n2\n"},
+		{"interpolación", "count = 3\nm = {\"a\": [1, 2]}\nprint \"Iteración: {count * 2} de {m.a} {{ok}\"", "Iteración: 6 de [1, 2] {ok}\n"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := runSource(t, tc.src); got != tc.want {
+				t.Errorf("salida = %q, se esperaba %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestInterpreterFunctionCall(t *testing.T) {
+	// function factorial(n) if n <= 1 return 1 end return n * factorial(n - 1) end
+	// print factorial(5)
+	n := &ast.VariableExpr{Name: "n"}
+	factorial := &ast.FunctionStmt{
+		Name:       "factorial",
+		Parameters: []*ast.Parameter{{Name: "n"}},
+		Body: []ast.Statement{
+			&ast.IfStmt{
+				Condition: &ast.BinaryExpr{Left: n, Operator: "<=", Right: &ast.LiteralExpr{Value: 1.0}},
+				ThenBlock: []ast.Statement{&ast.ReturnStmt{Value: &ast.LiteralExpr{Value: 1.0}}},
+			},
+			&ast.ReturnStmt{Value: &ast.BinaryExpr{
+				Left:     n,
+				Operator: "*",
+				Right: &ast.CallExpr{
+					Callee:    &ast.VariableExpr{Name: "factorial"},
+					Arguments: []ast.Expression{&ast.BinaryExpr{Left: n, Operator: "-", Right: &ast.LiteralExpr{Value: 1.0}}},
+				},
+			}},
+		},
+	}
+	prog := &ast.Program{Statements: []ast.Statement{
+		factorial,
+		&ast.PrintStmt{Value: &ast.CallExpr{
+			Callee:    &ast.VariableExpr{Name: "factorial"},
+			Arguments: []ast.Expression{&ast.LiteralExpr{Value: 5.0}},
+		}},
+	}}
+	if got := runProgram(t, prog); got != "120\n" {
+		t.Errorf("salida = %q, se esperaba %q", got, "120\n")
+	}
+}
+
+// TestInterpreterUncheckedJumps ejecuta ASTs armados a mano, que no pasan
+// por Check: un salto fuera de su función o ciclo es un error de ejecución.
+func TestInterpreterUncheckedJumps(t *testing.T) {
+	for _, stmt := range []ast.Statement{&ast.ReturnStmt{}, &ast.BreakStmt{}, &ast.ContinueStmt{}} {
+		prog := &ast.Program{Statements: []ast.Statement{stmt}}
+		err := interpreter.New(&bytes.Buffer{}).Run(prog)
+		if _, ok := err.(*interpreter.RuntimeError); !ok {
+			t.Errorf("%s: se esperaba *RuntimeError, se obtuvo %v", stmt.NodeType(), err)
+		}
+	}
+}
+
+// TestInterpreterRuntimeErrors comprueba el mensaje y la posición de los
+// errores de ejecución. Un error se ubica en la expresión más interna que
+// falló; end, si no es 0, es la columna donde termina.
+func TestInterpreterRuntimeErrors(t *testing.T) {
+	cases := []struct {
+		src       string
+		want      string
+		line, col int
+		end       int
+	}{
+		{"print y", "Variable no definida: 'y'", 1, 7, 0},
+		{"x = 1\ny = x + \"a\" - 2", "Operador '-' no aplicable a string y number", 2, 5, 16},
+		{"a = [1, 2]\nprint a[2]", "Índice fuera de rango: 2 (largo 2)", 2, 7, 0},
+		{"print [1, 2] * 1e7", "La lista replicada tendría más de 16777216 elementos", 1, 7, 0},
+		{"print [] * 1e12", "No se puede replicar una lista 1000000000000 veces", 1, 7, 0},
+		{"print [1] * (0 / 0)", "No se puede replicar una lista NaN veces", 1, 7, 0},
+		// La llamada que excede el límite es la recursiva, dentro de la función.
+		{"f = function(n)\n  return f(n + 1)\nend function\nf(0)", "Se superó la profundidad máxima de llamadas (10000)", 2, 10, 0},
+		{"function f(a, b = 1)\nend function\nf()", "'f' requiere al menos 1 argumentos, se pasaron 0", 3, 1, 0},
+		{"function f(a, b = 1)\nend function\nf(1, 2, 3)", "'f' recibe como máximo 2 argumentos, se pasaron 3", 3, 1, 0},
+		{"g = function(x)\nend function\ng(1, 2)", "función anónima recibe como máximo 1 argumentos, se pasaron 2", 3, 1, 0},
+		{"for x in 5\nend for", "No se puede iterar sobre un valor de tipo number", 1, 10, 0},
+		{"for i = 1 to 2 step 0\nend for", "El paso del for no puede ser 0", 1, 21, 0},
+		{"for i = 1 to 2 step \"a\"\nend for", "Se esperaba un número en el paso del for, se obtuvo string", 1, 1, 0},
+		{"r = range(1, 2, 0)", "range: 'step' no puede ser 0", 1, 5, 0},
+		{"r = range(\"a\")", "range: 'start' debe ser un número, se obtuvo string", 1, 5, 0},
+	}
+	for _, tc := range cases {
+		rtErr := runError(t, tc.src)
+		if rtErr.Message != tc.want || rtErr.Line != tc.line || rtErr.Column != tc.col {
+			t.Errorf("%q: error = %v, se esperaba %q en %d:%d", tc.src, rtErr, tc.want, tc.line, tc.col)
+		}
+		if tc.end != 0 && rtErr.Span.End.Column != tc.end {
+			t.Errorf("%q: el error termina en la columna %d, se esperaba %d", tc.src, rtErr.Span.End.Column, tc.end)
+		}
+	}
+}
+
+// TestInterpreterOnExampleFiles ejecuta cada ejemplo .ms que tenga un archivo
+// .out al lado y compara la salida impresa.
+func TestInterpreterOnExampleFiles(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("examples", "*.out"))
+	if err != nil {
+		t.Fatalf("Error buscando archivos de salida: %v", err)
+	}
+	if len(files) == 0 {
+		t.Fatalf("No se encontraron archivos .out en examples/")
+	}
+	for _, outFile := range files {
+		src := strings.TrimSuffix(outFile, ".out") + ".ms"
+		t.Run(filepath.Base(src), func(t *testing.T) {
+			code, err := os.ReadFile(src)
+			if err != nil {
+				t.Fatalf("No se pudo leer %s: %v", src, err)
+			}
+			want, err := os.ReadFile(outFile)
+			if err != nil {
+				t.Fatalf("No se pudo leer %s: %v", outFile, err)
+			}
+			if got := runSource(t, string(code)); got != string(want) {
+				t.Errorf("salida de %s:\n%s\nse esperaba:\n%s", src, got, want)
+			}
+		})
+	}
+}
+
+func TestInterpreterPrototypes(t *testing.T) {
+	src := `Animal = {"sound": "..."}
+function speak()
+  return self.name + " dice " + self.sound
+end function
+Animal.speak = @speak
+
+Dog = new Animal
+Dog.sound = "guau"
+function dogSpeak()
+  return super.speak() + "!"
+end function
+Dog.speak = @dogSpeak
+
+d = new Dog
+d.name = "Rex"
+print d.speak()
+print d.speak
+print @d.speak
+print d isa Dog
+print d isa Animal
+print Dog isa d
+print 1 isa Animal
+print d.__isa == Dog
+`
+	want := "Rex dice guau!\nRex dice guau!\nFUNCTION()\ntrue\ntrue\nfalse\nfalse\ntrue\n"
+	if got := runSource(t, src); got != want {
+		t.Errorf("salida = %q, se esperaba %q", got, want)
+	}
+}
+
+func TestInterpreterFirstClassFunctions(t *testing.T) {
+	src := `function contador()
+  n = 0
+  inc = function()
+    outer.n = outer.n + 1
+    return outer.n
+  end function
+  return @inc
+end function
+
+c = contador
+c
+print c
+otro = contador
+print otro
+
+doble = function(x)
+  return x * 2
+end function
+function aplica(f, x)
+  return f(x)
+end function
+print aplica(@doble, 5)
+
+ops = {"doble": @doble, "lista": [@doble]}
+print ops.doble(4)
+print ops.lista[0](1)
+
+function saluda()
+  print "hola"
+end function
+saluda
+g = @saluda
+print @g == @saluda
+function(x)
+  return x
+end function
+`
+	want := "2\n1\n10\n8\n2\nhola\ntrue\n"
+	if got := runSource(t, src); got != want {
+		t.Errorf("salida = %q, se esperaba %q", got, want)
+	}
+}
+
+func TestInterpreterDefaultAndVariadicParameters(t *testing.T) {
+	src := `function f(a, b = 10, c = a + b)
+  return [a, b, c]
+end function
+print f(1)
+print f(1, 2)
+print f(1, 2, 3)
+
+function junta(sep, partes...)
+  r = ""
+  for i = 0 to 2
+    if i > 0
+      r = r + sep
+    end if
+    r = r + partes[i]
+  end for
+  return [r, partes]
+end function
+print junta("-", "a", "b", "c")
+print @f
+print @junta
+`
+	want := "[1, 10, 11]\n[1, 2, 3]\n[1, 2, 3]\n[\"a-b-c\", [\"a\", \"b\", \"c\"]]\nFUNCTION(a, b=10, c=…)\nFUNCTION(sep, partes...)\n"
+	if got := runSource(t, src); got != want {
+		t.Errorf("salida = %q, se esperaba %q", got, want)
+	}
+}
+
+func TestInterpreterCompoundAssignment(t *testing.T) {
+	src := `x = 10
+x += 5
+x -= 3
+x *= 2
+x /= 4
+x %= 4
+x ^= 3
+print x
+s = "a"
+s += "b"
+print s
+idx = 0
+function siguiente()
+  outer.idx += 1
+  return outer.idx - 1
+end function
+a = [1, 2, 3]
+a[siguiente] += 10
+a[-1] *= 2
+print a
+print idx
+m = {"n": 1}
+m.n += 1
+m["n"] -= 5
+print m.n
+Base = {"cuenta": 1}
+obj = new Base
+obj.cuenta += 1
+print [Base.cuenta, obj.cuenta]
+`
+	want := "8\nab\n[11, 2, 6]\n1\n-3\n[1, 2]\n"
+	if got := runSource(t, src); got != want {
+		t.Errorf("salida = %q, se esperaba %q", got, want)
+	}
+}
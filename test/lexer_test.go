@@ -1,8 +1,11 @@
 package test
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/DAlfaroV/miniscript/internal/lexer"
@@ -48,3 +51,430 @@ func TestLexerOnExampleFiles(t *testing.T) {
 		})
 	}
 }
+
+func TestLexerErrorRecovery(t *testing.T) {
+	src := "x = 1 $ 2\ny = !3\nprint \"sin cerrar"
+	tokens, err := lexer.NewLexer(src, lexer.WithErrorRecovery()).ScanTokens()
+
+	var errs lexer.ErrorList
+	if !errors.As(err, &errs) {
+		t.Fatalf("se esperaba lexer.ErrorList, se obtuvo %v", err)
+	}
+	if len(errs) != 3 {
+		t.Fatalf("se esperaban 3 errores, se obtuvieron %d: %v", len(errs), errs)
+	}
+
+	illegal := 0
+	for _, tok := range tokens {
+		if tok.Type == lexer.TOKEN_ILLEGAL {
+			illegal++
+		}
+	}
+	if illegal != 3 {
+		t.Errorf("se esperaban 3 TOKEN_ILLEGAL, se obtuvieron %d", illegal)
+	}
+	if last := tokens[len(tokens)-1]; last.Type != lexer.TOKEN_EOF {
+		t.Errorf("el último token no es EOF, es %v", last.Type)
+	}
+	// Los tokens válidos posteriores a un error se conservan.
+	if tokens[4].Type != lexer.TOKEN_NUMBER || tokens[4].Literal != 2.0 {
+		t.Errorf("se esperaba el número 2 tras el error, se obtuvo %v", tokens[4])
+	}
+}
+
+func TestLexerStopsOnFirstErrorByDefault(t *testing.T) {
+	tokens, err := lexer.NewLexer("x = $ 1").ScanTokens()
+	if tokens != nil {
+		t.Errorf("se esperaban tokens nil, se obtuvieron %d", len(tokens))
+	}
+	if _, ok := err.(*lexer.LexError); !ok {
+		t.Errorf("se esperaba *LexError, se obtuvo %v", err)
+	}
+}
+
+func TestLexerUTF8(t *testing.T) {
+	src := "año = \"Iteración\"\nñandú_2 = año"
+	tokens, err := lexer.NewLexer(src).ScanTokens()
+	if err != nil {
+		t.Fatalf("Error léxico: %v", err)
+	}
+
+	want := []struct {
+		typ     lexer.TokenType
+		lexeme  string
+		literal interface{}
+	}{
+		{lexer.TOKEN_IDENTIFIER, "año", "año"},
+		{lexer.TOKEN_ASSIGN, "=", nil},
+		{lexer.TOKEN_STRING, `"Iteración"`, "Iteración"},
+		{lexer.TOKEN_NEWLINE, "\n", nil},
+		{lexer.TOKEN_IDENTIFIER, "ñandú_2", "ñandú_2"},
+		{lexer.TOKEN_ASSIGN, "=", nil},
+		{lexer.TOKEN_IDENTIFIER, "año", "año"},
+		{lexer.TOKEN_EOF, "", nil},
+	}
+	if len(tokens) != len(want) {
+		t.Fatalf("se esperaban %d tokens, se obtuvieron %d: %v", len(want), len(tokens), tokens)
+	}
+	for i, w := range want {
+		tok := tokens[i]
+		if tok.Type != w.typ || tok.Lexeme != w.lexeme || tok.Literal != w.literal {
+			t.Errorf("token %d = {%v %q %v}, se esperaba {%v %q %v}",
+				i, tok.Type, tok.Lexeme, tok.Literal, w.typ, w.lexeme, w.literal)
+		}
+	}
+
+	// Las columnas se cuentan en caracteres, no en bytes.
+	eof := tokens[len(tokens)-1]
+	if eof.Line != 2 || eof.Column != 14 {
+		t.Errorf("EOF en %d:%d, se esperaba 2:14", eof.Line, eof.Column)
+	}
+}
+
+func TestLexerMultibyteUnexpectedCharacter(t *testing.T) {
+	_, err := lexer.NewLexer("x = 1 € 2").ScanTokens()
+	lexErr, ok := err.(*lexer.LexError)
+	if !ok {
+		t.Fatalf("se esperaba *LexError, se obtuvo %v", err)
+	}
+	if lexErr.Message != "Unexpected character '€'" {
+		t.Errorf("mensaje = %q", lexErr.Message)
+	}
+}
+
+func TestLexerInvalidUTF8(t *testing.T) {
+	_, err := lexer.NewLexer("x = \xff").ScanTokens()
+	lexErr, ok := err.(*lexer.LexError)
+	if !ok {
+		t.Fatalf("se esperaba *LexError, se obtuvo %v", err)
+	}
+	if lexErr.Message != "Invalid UTF-8 encoding" {
+		t.Errorf("mensaje = %q", lexErr.Message)
+	}
+}
+
+func TestLexerTokenSpans(t *testing.T) {
+	src := "x = 10\nmsg = \"dos\nlíneas\""
+	tokens, err := lexer.NewLexer(src).ScanTokens()
+	if err != nil {
+		t.Fatalf("Error léxico: %v", err)
+	}
+
+	want := []lexer.Span{
+		{Start: lexer.Position{Line: 1, Column: 1, Offset: 0}, End: lexer.Position{Line: 1, Column: 2, Offset: 1}},
+		{Start: lexer.Position{Line: 1, Column: 3, Offset: 2}, End: lexer.Position{Line: 1, Column: 4, Offset: 3}},
+		{Start: lexer.Position{Line: 1, Column: 5, Offset: 4}, End: lexer.Position{Line: 1, Column: 7, Offset: 6}},
+		// El salto de línea termina en el inicio de la línea siguiente.
+		{Start: lexer.Position{Line: 1, Column: 7, Offset: 6}, End: lexer.Position{Line: 2, Column: 1, Offset: 7}},
+		{Start: lexer.Position{Line: 2, Column: 1, Offset: 7}, End: lexer.Position{Line: 2, Column: 4, Offset: 10}},
+		{Start: lexer.Position{Line: 2, Column: 5, Offset: 11}, End: lexer.Position{Line: 2, Column: 6, Offset: 12}},
+		// El string ocupa dos líneas: empieza en la 2 y termina en la 3.
+		{Start: lexer.Position{Line: 2, Column: 7, Offset: 13}, End: lexer.Position{Line: 3, Column: 8, Offset: 26}},
+	}
+	for i, w := range want {
+		if tokens[i].Span != w {
+			t.Errorf("token %d (%q): span = %+v, se esperaba %+v", i, tokens[i].Lexeme, tokens[i].Span, w)
+		}
+		if tokens[i].Line != w.Start.Line || tokens[i].Column != w.Start.Column {
+			t.Errorf("token %d (%q): posición %d:%d, se esperaba el inicio %d:%d",
+				i, tokens[i].Lexeme, tokens[i].Line, tokens[i].Column, w.Start.Line, w.Start.Column)
+		}
+	}
+}
+
+func TestLexerCompoundAssignment(t *testing.T) {
+	tokens, err := lexer.NewLexer("+= -= *= /= %= ^= + / // comentario").ScanTokens()
+	if err != nil {
+		t.Fatalf("Error léxico: %v", err)
+	}
+	want := []lexer.TokenType{
+		lexer.TOKEN_PLUS_ASSIGN, lexer.TOKEN_MINUS_ASSIGN, lexer.TOKEN_ASTERISK_ASSIGN,
+		lexer.TOKEN_SLASH_ASSIGN, lexer.TOKEN_PERCENT_ASSIGN, lexer.TOKEN_CARET_ASSIGN,
+		lexer.TOKEN_PLUS, lexer.TOKEN_SLASH, lexer.TOKEN_EOF,
+	}
+	if len(tokens) != len(want) {
+		t.Fatalf("se esperaban %d tokens, se obtuvieron %d", len(want), len(tokens))
+	}
+	for i, typ := range want {
+		if tokens[i].Type != typ {
+			t.Errorf("token %d = %v, se esperaba %v", i, tokens[i].Type, typ)
+		}
+	}
+}
+
+func TestLexerNumericLiterals(t *testing.T) {
+	tests := []struct {
+		src  string
+		want float64
+	}{
+		{"42", 42},
+		{"3.14", 3.14},
+		{".5", 0.5},
+		{"1e6", 1e6},
+		{"1.5e-3", 1.5e-3},
+		{"2E+2", 200},
+		{"0xFF", 255},
+		{"0Xff", 255},
+		{"0b1010", 10},
+		{"1_000_000", 1000000},
+		{"0xFF_FF", 65535},
+		{"1_0.2_5e1_0", 10.25e10},
+	}
+	for _, tt := range tests {
+		tokens, err := lexer.NewLexer(tt.src).ScanTokens()
+		if err != nil {
+			t.Errorf("%q: error léxico inesperado: %v", tt.src, err)
+			continue
+		}
+		if len(tokens) != 2 || tokens[0].Type != lexer.TOKEN_NUMBER {
+			t.Errorf("%q: se esperaba un único TOKEN_NUMBER, se obtuvo %v", tt.src, tokens)
+			continue
+		}
+		if tokens[0].Literal != tt.want {
+			t.Errorf("%q: literal = %v, se esperaba %v", tt.src, tokens[0].Literal, tt.want)
+		}
+	}
+}
+
+func TestLexerMalformedNumbers(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"1e", "Malformed number '1e': exponent has no digits"},
+		{"1e+", "Malformed number '1e+': exponent has no digits"},
+		{"0x", "Malformed number '0x': hexadecimal literal has no digits"},
+		{"0b102", "Malformed number '0b102': invalid digit '2' in binary literal"},
+		{"1_", "Malformed number '1_': '_' must be placed between digits"},
+		{"1__0", "Malformed number '1__0': '_' must be placed between digits"},
+		{"12abc", "Malformed number '12abc': unexpected character 'a'"},
+	}
+	for _, tt := range tests {
+		_, err := lexer.NewLexer(tt.src).ScanTokens()
+		var lexErr *lexer.LexError
+		if !errors.As(err, &lexErr) {
+			t.Errorf("%q: se esperaba *LexError, se obtuvo %v", tt.src, err)
+			continue
+		}
+		if lexErr.Message != tt.want {
+			t.Errorf("%q: mensaje = %q, se esperaba %q", tt.src, lexErr.Message, tt.want)
+		}
+	}
+}
+
+func TestLexerStringEscapes(t *testing.T) {
+	tests := []struct {
+		src  string
+		opts []lexer.Option
+		want string
+	}{
+		{`"a""b"`, nil, `a"b`},
+		{`"{{x}"`, nil, `{x}`},
+		{`"a\nb"`, nil, `a\nb`},
+		{`"a\nb\t\"c\"\\"`, []lexer.Option{lexer.WithEscapes()}, "a\nb\t\"c\"\\"},
+		{`"café \{x\}"`, []lexer.Option{lexer.WithEscapes()}, "café {x}"},
+	}
+	for _, tt := range tests {
+		tokens, err := lexer.NewLexer(tt.src, tt.opts...).ScanTokens()
+		if err != nil {
+			t.Errorf("%s: error léxico inesperado: %v", tt.src, err)
+			continue
+		}
+		if tokens[0].Type != lexer.TOKEN_STRING || tokens[0].Literal != tt.want {
+			t.Errorf("%s: se obtuvo %v %q, se esperaba STRING %q", tt.src, tokens[0].Type, tokens[0].Literal, tt.want)
+		}
+	}
+}
+
+func TestLexerInvalidEscapes(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{`"a\qb"`, `Invalid escape sequence '\q': unknown escape`},
+		{`"a\u12"`, `Invalid escape sequence '\u12': expected 4 hexadecimal digits`},
+	}
+	for _, tt := range tests {
+		_, err := lexer.NewLexer(tt.src, lexer.WithEscapes()).ScanTokens()
+		var lexErr *lexer.LexError
+		if !errors.As(err, &lexErr) {
+			t.Errorf("%s: se esperaba *LexError, se obtuvo %v", tt.src, err)
+			continue
+		}
+		if lexErr.Message != tt.want || lexErr.Column != 3 {
+			t.Errorf("%s: error = %q en columna %d, se esperaba %q en columna 3", tt.src, lexErr.Message, lexErr.Column, tt.want)
+		}
+	}
+}
+
+func TestLexerStringInterpolation(t *testing.T) {
+	tokens, err := lexer.NewLexer(`"Total: {m["n"] + 1}!"`).ScanTokens()
+	if err != nil {
+		t.Fatalf("Error léxico: %v", err)
+	}
+	if tokens[0].Type != lexer.TOKEN_TEMPLATE {
+		t.Fatalf("se esperaba TEMPLATE, se obtuvo %v", tokens[0].Type)
+	}
+	parts := tokens[0].Literal.([]lexer.TemplatePart)
+	if len(parts) != 3 || parts[0].Text != "Total: " || parts[2].Text != "!" {
+		t.Fatalf("partes = %v", parts)
+	}
+	want := []lexer.TokenType{
+		lexer.TOKEN_IDENTIFIER, lexer.TOKEN_LBRACKET, lexer.TOKEN_STRING, lexer.TOKEN_RBRACKET,
+		lexer.TOKEN_PLUS, lexer.TOKEN_NUMBER, lexer.TOKEN_EOF,
+	}
+	expr := parts[1].Tokens
+	if len(expr) != len(want) {
+		t.Fatalf("se esperaban %d tokens en la interpolación, se obtuvieron %v", len(want), parts[1])
+	}
+	for i, typ := range want {
+		if expr[i].Type != typ {
+			t.Errorf("token %d = %v, se esperaba %v", i, expr[i].Type, typ)
+		}
+	}
+	// Las posiciones son las del archivo, no las de la interpolación.
+	if expr[0].Column != 10 {
+		t.Errorf("columna de 'm' = %d, se esperaba 10", expr[0].Column)
+	}
+
+	// '{{' y '}}' son llaves literales, también junto a una interpolación.
+	tokens, err = lexer.NewLexer(`"{{x}} {{{y}}}"`).ScanTokens()
+	if err != nil {
+		t.Fatalf("Error léxico: %v", err)
+	}
+	parts = tokens[0].Literal.([]lexer.TemplatePart)
+	if len(parts) != 3 || parts[0].Text != "{x} {" || parts[1].Tokens[0].Lexeme != "y" || parts[2].Text != "}" {
+		t.Errorf("partes = %v", parts)
+	}
+
+	for _, src := range []string{`"a {} b"`, `"a {x b"`} {
+		if _, err := lexer.NewLexer(src).ScanTokens(); err == nil {
+			t.Errorf("%s: se esperaba un error léxico", src)
+		}
+	}
+}
+
+func TestLexerTriviaRoundTrip(t *testing.T) {
+	sources := []string{
+		"x = 1 // uno\n\n/* bloque\n   de dos líneas */ y = x\t+ 2\n/// doc\nfunction f()\nend function\n",
+		"print \"a {x} b\"  ",
+		"x = 1 /* c\n */ y = 2\n",
+	}
+	files, _ := filepath.Glob("examples/*.ms")
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("No se pudo leer %s: %v", file, err)
+		}
+		sources = append(sources, string(src))
+	}
+
+	for _, src := range sources {
+		tokens, err := lexer.NewLexer(src, lexer.WithTrivia()).ScanTokens()
+		if err != nil {
+			t.Fatalf("Error léxico: %v", err)
+		}
+		var sb strings.Builder
+		for _, tok := range tokens {
+			for _, trivia := range tok.LeadingTrivia {
+				sb.WriteString(trivia.Text)
+			}
+			sb.WriteString(tok.Lexeme)
+		}
+		if sb.String() != src {
+			t.Errorf("la reconstrucción no coincide:\n%q\nse esperaba\n%q", sb.String(), src)
+		}
+	}
+}
+
+func TestLexerTriviaKinds(t *testing.T) {
+	tokens, err := lexer.NewLexer("/* a */ // b\n/// c\nx", lexer.WithTrivia()).ScanTokens()
+	if err != nil {
+		t.Fatalf("Error léxico: %v", err)
+	}
+	want := []lexer.TriviaKind{
+		lexer.TRIVIA_BLOCK_COMMENT, lexer.TRIVIA_WHITESPACE, lexer.TRIVIA_COMMENT,
+		lexer.TRIVIA_NEWLINE, lexer.TRIVIA_DOC_COMMENT, lexer.TRIVIA_NEWLINE,
+	}
+	trivia := tokens[0].LeadingTrivia
+	if len(trivia) != len(want) {
+		t.Fatalf("se esperaban %d trivia, se obtuvieron %v", len(want), trivia)
+	}
+	for i, kind := range want {
+		if trivia[i].Kind != kind {
+			t.Errorf("trivia %d = %v, se esperaba %v", i, trivia[i].Kind, kind)
+		}
+	}
+
+	// Sin WithTrivia solo se conservan los comentarios de documentación.
+	tokens, err = lexer.NewLexer("/* a */ // b\n/// c\nx").ScanTokens()
+	if err != nil {
+		t.Fatalf("Error léxico: %v", err)
+	}
+	if len(tokens[0].LeadingTrivia) != 1 || tokens[0].LeadingTrivia[0].Text != "/// c" {
+		t.Errorf("trivia sin WithTrivia = %v", tokens[0].LeadingTrivia)
+	}
+	if tokens[0].Line != 3 {
+		t.Errorf("línea de 'x' = %d, se esperaba 3", tokens[0].Line)
+	}
+}
+
+func TestLexerUnterminatedBlockComment(t *testing.T) {
+	_, err := lexer.NewLexer("x = 1\n/* sin cierre\n").ScanTokens()
+	var lexErr *lexer.LexError
+	if !errors.As(err, &lexErr) || lexErr.Message != "Unterminated block comment" || lexErr.Line != 2 {
+		t.Errorf("se esperaba 'Unterminated block comment' en la línea 2, se obtuvo %v", err)
+	}
+}
+
+func TestLexerNewlineTokens(t *testing.T) {
+	tests := []struct {
+		src  string
+		want []lexer.TokenType
+	}{
+		// Las líneas en blanco se colapsan y no hay NEWLINE al inicio.
+		{"\n\nx\n\n\ny", []lexer.TokenType{lexer.TOKEN_IDENTIFIER, lexer.TOKEN_NEWLINE, lexer.TOKEN_IDENTIFIER}},
+		{"x = 1\n-2", []lexer.TokenType{
+			lexer.TOKEN_IDENTIFIER, lexer.TOKEN_ASSIGN, lexer.TOKEN_NUMBER, lexer.TOKEN_NEWLINE,
+			lexer.TOKEN_MINUS, lexer.TOKEN_NUMBER,
+		}},
+		// Tras un operador o una coma la sentencia continúa.
+		{"x = 1 -\n2", []lexer.TokenType{
+			lexer.TOKEN_IDENTIFIER, lexer.TOKEN_ASSIGN, lexer.TOKEN_NUMBER, lexer.TOKEN_MINUS, lexer.TOKEN_NUMBER,
+		}},
+		{"f 1,\n2", []lexer.TokenType{
+			lexer.TOKEN_IDENTIFIER, lexer.TOKEN_NUMBER, lexer.TOKEN_COMMA, lexer.TOKEN_NUMBER,
+		}},
+		// Dentro de paréntesis, corchetes o llaves tampoco termina.
+		{"[1\n, 2\n]\n", []lexer.TokenType{
+			lexer.TOKEN_LBRACKET, lexer.TOKEN_NUMBER, lexer.TOKEN_COMMA, lexer.TOKEN_NUMBER,
+			lexer.TOKEN_RBRACKET, lexer.TOKEN_NEWLINE,
+		}},
+		{"x;\ny", []lexer.TokenType{lexer.TOKEN_IDENTIFIER, lexer.TOKEN_SEMICOLON, lexer.TOKEN_IDENTIFIER}},
+		// Un comentario de bloque con saltos de línea termina la sentencia.
+		{"x /* a\n b */ y", []lexer.TokenType{lexer.TOKEN_IDENTIFIER, lexer.TOKEN_NEWLINE, lexer.TOKEN_IDENTIFIER}},
+		{"x /* a */ y", []lexer.TokenType{lexer.TOKEN_IDENTIFIER, lexer.TOKEN_IDENTIFIER}},
+		{"x +/* a\n b */ y", []lexer.TokenType{lexer.TOKEN_IDENTIFIER, lexer.TOKEN_PLUS, lexer.TOKEN_IDENTIFIER}},
+		// El cuerpo de una función pasada como argumento conserva sus NEWLINE.
+		{"f(function()\nreturn 1\nend function)", []lexer.TokenType{
+			lexer.TOKEN_IDENTIFIER, lexer.TOKEN_LPAREN, lexer.TOKEN_FUNCTION, lexer.TOKEN_LPAREN, lexer.TOKEN_RPAREN,
+			lexer.TOKEN_NEWLINE, lexer.TOKEN_RETURN, lexer.TOKEN_NUMBER, lexer.TOKEN_NEWLINE,
+			lexer.TOKEN_END, lexer.TOKEN_FUNCTION, lexer.TOKEN_RPAREN,
+		}},
+	}
+	for _, tt := range tests {
+		tokens, err := lexer.NewLexer(tt.src).ScanTokens()
+		if err != nil {
+			t.Errorf("%q: error léxico inesperado: %v", tt.src, err)
+			continue
+		}
+		want := append(tt.want, lexer.TOKEN_EOF)
+		got := make([]lexer.TokenType, len(tokens))
+		for i, tok := range tokens {
+			got[i] = tok.Type
+		}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("%q: tokens = %v, se esperaba %v", tt.src, got, want)
+		}
+	}
+}
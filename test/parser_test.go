@@ -0,0 +1,481 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DAlfaroV/miniscript/internal/lexer"
+	"github.com/DAlfaroV/miniscript/internal/parser"
+	"github.com/DAlfaroV/miniscript/internal/parser/ast"
+)
+
+// parseSource lexea y parsea src, fallando el test ante errores léxicos.
+func parseSource(t *testing.T, src string) (*ast.Program, parser.ErrorList) {
+	t.Helper()
+	tokens, err := lexer.NewLexer(src).ScanTokens()
+	if err != nil {
+		t.Fatalf("Error léxico: %v", err)
+	}
+	prog, err := parser.New(tokens).ParseProgram()
+	if err == nil {
+		return prog, nil
+	}
+	var errs parser.ErrorList
+	if !errors.As(err, &errs) {
+		t.Fatalf("se esperaba parser.ErrorList, se obtuvo %T", err)
+	}
+	return prog, errs
+}
+
+func TestParseErrorPosition(t *testing.T) {
+	_, errs := parseSource(t, "x = 1\ny = (2 + 3\n")
+	if len(errs) != 1 {
+		t.Fatalf("se esperaba 1 error, se obtuvieron %d: %v", len(errs), errs)
+	}
+	if errs[0].Line != 3 {
+		t.Errorf("Line = %d, se esperaba 3", errs[0].Line)
+	}
+}
+
+func TestParseErrorUnexpectedToken(t *testing.T) {
+	_, errs := parseSource(t, "x = * 2")
+	if len(errs) != 1 {
+		t.Fatalf("se esperaba 1 error, se obtuvieron %d: %v", len(errs), errs)
+	}
+	if errs[0].Line != 1 {
+		t.Errorf("Line = %d, se esperaba 1", errs[0].Line)
+	}
+}
+
+func TestParseRecoversMultipleErrors(t *testing.T) {
+	src := "x = * 2\n" +
+		"print x\n" +
+		"while x < 3\n" +
+		"  y = )\n" +
+		"  x = x + 1\n" +
+		"end\n" +
+		"z = ("
+	prog, errs := parseSource(t, src)
+	if len(errs) != 3 {
+		t.Fatalf("se esperaban 3 errores, se obtuvieron %d: %v", len(errs), errs)
+	}
+	wantLines := []int{1, 4, 7}
+	for i, line := range wantLines {
+		if errs[i].Line != line {
+			t.Errorf("error %d en línea %d, se esperaba %d", i, errs[i].Line, line)
+		}
+	}
+	// El programa parcial conserva las sentencias válidas.
+	if len(prog.Statements) != 2 {
+		t.Fatalf("se esperaban 2 sentencias, se obtuvieron %d", len(prog.Statements))
+	}
+	loop, ok := prog.Statements[1].(*ast.WhileStmt)
+	if !ok || len(loop.Body) != 1 {
+		t.Errorf("el while debería conservar una sentencia válida: %#v", prog.Statements[1])
+	}
+}
+
+func TestParseNodeSpans(t *testing.T) {
+	prog, errs := parseSource(t, "total = 1 + 2\nwhile total < 10\n  total = total * 2\nend")
+	if errs != nil {
+		t.Fatalf("errores inesperados: %v", errs)
+	}
+
+	assign := prog.Statements[0].(*ast.AssignmentStmt)
+	if got := assign.Span; got.Start.Line != 1 || got.Start.Column != 1 || got.End.Column != 14 {
+		t.Errorf("span de la asignación = %+v", got)
+	}
+	sum := assign.Value.(*ast.BinaryExpr)
+	if got := sum.Span; got.Start.Column != 9 || got.End.Column != 14 {
+		t.Errorf("span de la suma = %+v", got)
+	}
+
+	loop := prog.Statements[1].(*ast.WhileStmt)
+	if got := loop.Span; got.Start.Line != 2 || got.End.Line != 4 || got.End.Column != 4 {
+		t.Errorf("span del while = %+v", got)
+	}
+}
+
+func TestParseTypedBlockTerminators(t *testing.T) {
+	src := "for i = 1 to 3\n" +
+		"  while i < 2\n" +
+		"    i = i + 1\n" +
+		"  end while\n" +
+		"  if i == 2\n" +
+		"    print i\n" +
+		"  else\n" +
+		"    print 0\n" +
+		"  end if\n" +
+		"end for\n" +
+		"function f(x)\n" +
+		"  return x\n" +
+		"end function\n" +
+		"while false\n" +
+		"end"
+	prog, errs := parseSource(t, src)
+	if errs != nil {
+		t.Fatalf("errores inesperados: %v", errs)
+	}
+	if len(prog.Statements) != 3 {
+		t.Fatalf("se esperaban 3 sentencias, se obtuvieron %d", len(prog.Statements))
+	}
+	loop := prog.Statements[0].(*ast.ForStmt)
+	if len(loop.Body) != 2 {
+		t.Errorf("el for debería tener 2 sentencias, tiene %d", len(loop.Body))
+	}
+}
+
+func TestParseMismatchedBlockTerminator(t *testing.T) {
+	src := "x = 0\n" +
+		"while x < 3\n" +
+		"  x = x + 1\n" +
+		"end for\n" +
+		"print x"
+	prog, errs := parseSource(t, src)
+	if len(errs) != 1 {
+		t.Fatalf("se esperaba 1 error, se obtuvieron %d: %v", len(errs), errs)
+	}
+	if errs[0].Line != 4 || errs[0].Column != 5 {
+		t.Errorf("error en %d:%d, se esperaba 4:5", errs[0].Line, errs[0].Column)
+	}
+	want := "'end for' no coincide con 'while' abierto en línea 2, columna 1"
+	if errs[0].Message != want {
+		t.Errorf("mensaje = %q, se esperaba %q", errs[0].Message, want)
+	}
+	// El bloque se cierra igualmente y el resto del programa se analiza.
+	if len(prog.Statements) != 3 {
+		t.Errorf("se esperaban 3 sentencias, se obtuvieron %d", len(prog.Statements))
+	}
+}
+
+func TestParseCallExpressions(t *testing.T) {
+	prog, errs := parseSource(t, "f(1, 2)(3)\nobj.metodo(x).otro\nfoo 1, \"a\"\nbar -1")
+	if errs != nil {
+		t.Fatalf("errores inesperados: %v", errs)
+	}
+	if len(prog.Statements) != 4 {
+		t.Fatalf("se esperaban 4 sentencias, se obtuvieron %d", len(prog.Statements))
+	}
+
+	chained := prog.Statements[0].(*ast.ExpressionStmt).Expr.(*ast.CallExpr)
+	inner, ok := chained.Callee.(*ast.CallExpr)
+	if !ok || len(inner.Arguments) != 2 || len(chained.Arguments) != 1 {
+		t.Errorf("llamada encadenada mal formada: %#v", chained)
+	}
+
+	member := prog.Statements[1].(*ast.ExpressionStmt).Expr.(*ast.MemberExpr)
+	if member.Name != "otro" {
+		t.Errorf("miembro = %q, se esperaba \"otro\"", member.Name)
+	}
+	method := member.Object.(*ast.CallExpr).Callee.(*ast.MemberExpr)
+	if method.Name != "metodo" {
+		t.Errorf("método = %q, se esperaba \"metodo\"", method.Name)
+	}
+
+	stmtCall := prog.Statements[2].(*ast.ExpressionStmt).Expr.(*ast.CallExpr)
+	if len(stmtCall.Arguments) != 2 {
+		t.Errorf("la llamada sin paréntesis debería tener 2 argumentos, tiene %d", len(stmtCall.Arguments))
+	}
+
+	// 'bar -1' es una resta, no una llamada.
+	if _, ok := prog.Statements[3].(*ast.ExpressionStmt).Expr.(*ast.BinaryExpr); !ok {
+		t.Errorf("'bar -1' debería ser una BinaryExpr")
+	}
+}
+
+func TestParseListIndexAndSlice(t *testing.T) {
+	prog, errs := parseSource(t, "a = [1, 2, 3,]\na[0] = a[1:]\nb = a[:2]")
+	if errs != nil {
+		t.Fatalf("errores inesperados: %v", errs)
+	}
+	list := prog.Statements[0].(*ast.AssignmentStmt).Value.(*ast.ListExpr)
+	if len(list.Elements) != 3 {
+		t.Errorf("la lista debería tener 3 elementos, tiene %d", len(list.Elements))
+	}
+	assign := prog.Statements[1].(*ast.IndexAssignStmt)
+	slice := assign.Value.(*ast.SliceExpr)
+	if slice.Start == nil || slice.End != nil {
+		t.Errorf("el corte 'a[1:]' debería tener solo inicio: %#v", slice)
+	}
+	slice = prog.Statements[2].(*ast.AssignmentStmt).Value.(*ast.SliceExpr)
+	if slice.Start != nil || slice.End == nil {
+		t.Errorf("el corte 'a[:2]' debería tener solo final: %#v", slice)
+	}
+}
+
+func TestParseInvalidAssignmentTarget(t *testing.T) {
+	_, errs := parseSource(t, "f(1) = 2")
+	if len(errs) != 1 || errs[0].Message != "Destino de asignación inválido" {
+		t.Fatalf("se esperaba error de destino inválido, se obtuvo %v", errs)
+	}
+}
+
+func TestParseMapAndMembers(t *testing.T) {
+	prog, errs := parseSource(t, "m = {\"a\": 1, \"b\": {\"c\": 2},}\nm.b.c = m[\"a\"]")
+	if errs != nil {
+		t.Fatalf("errores inesperados: %v", errs)
+	}
+	lit := prog.Statements[0].(*ast.AssignmentStmt).Value.(*ast.MapExpr)
+	if len(lit.Keys) != 2 || len(lit.Values) != 2 {
+		t.Errorf("el mapa debería tener 2 entradas: %#v", lit)
+	}
+	assign := prog.Statements[1].(*ast.MemberAssignStmt)
+	if assign.Name != "c" {
+		t.Errorf("miembro asignado = %q, se esperaba \"c\"", assign.Name)
+	}
+	if obj, ok := assign.Object.(*ast.MemberExpr); !ok || obj.Name != "b" {
+		t.Errorf("objeto de la asignación mal formado: %#v", assign.Object)
+	}
+}
+
+func TestParseParameters(t *testing.T) {
+	prog, errs := parseSource(t, "function f(a, b = 2, resto...)\nend function")
+	if errs != nil {
+		t.Fatalf("errores inesperados: %v", errs)
+	}
+	params := prog.Statements[0].(*ast.FunctionStmt).Parameters
+	if len(params) != 3 {
+		t.Fatalf("se esperaban 3 parámetros, se obtuvieron %d", len(params))
+	}
+	if params[0].Default != nil || params[1].Default == nil || !params[2].Variadic {
+		t.Errorf("parámetros mal formados: %+v %+v %+v", params[0], params[1], params[2])
+	}
+
+	invalid := []string{
+		"function f(a = 1, b)\nend function",
+		"function f(a..., b)\nend function",
+	}
+	for _, src := range invalid {
+		if _, errs := parseSource(t, src); len(errs) == 0 {
+			t.Errorf("se esperaba un error de sintaxis en %q", src)
+		}
+	}
+}
+
+func TestParsePowerPrecedence(t *testing.T) {
+	prog, errs := parseSource(t, "a = -2 ^ 3 ^ 2\nb = 2 * x ^ 2")
+	if errs != nil {
+		t.Fatalf("errores inesperados: %v", errs)
+	}
+
+	// -2 ^ 3 ^ 2  =>  -(2 ^ (3 ^ 2))
+	neg := prog.Statements[0].(*ast.AssignmentStmt).Value.(*ast.UnaryExpr)
+	pow := neg.Right.(*ast.BinaryExpr)
+	if pow.Operator != "^" {
+		t.Fatalf("se esperaba '^' bajo el menos unario, se obtuvo %q", pow.Operator)
+	}
+	if _, ok := pow.Left.(*ast.LiteralExpr); !ok {
+		t.Errorf("la base debería ser el literal 2: %#v", pow.Left)
+	}
+	if inner, ok := pow.Right.(*ast.BinaryExpr); !ok || inner.Operator != "^" {
+		t.Errorf("el exponente debería ser 3 ^ 2: %#v", pow.Right)
+	}
+
+	// 2 * x ^ 2  =>  2 * (x ^ 2)
+	mul := prog.Statements[1].(*ast.AssignmentStmt).Value.(*ast.BinaryExpr)
+	if mul.Operator != "*" {
+		t.Fatalf("se esperaba '*' en la raíz, se obtuvo %q", mul.Operator)
+	}
+	if right, ok := mul.Right.(*ast.BinaryExpr); !ok || right.Operator != "^" {
+		t.Errorf("el operando derecho debería ser x ^ 2: %#v", mul.Right)
+	}
+}
+
+func TestParseInterpolatedString(t *testing.T) {
+	prog, errs := parseSource(t, `print "a {x + 1} b"`)
+	if len(errs) > 0 {
+		t.Fatalf("errores inesperados: %v", errs)
+	}
+	expr, ok := prog.Statements[0].(*ast.PrintStmt).Value.(*ast.InterpolatedStringExpr)
+	if !ok || len(expr.Parts) != 3 {
+		t.Fatalf("se esperaba un InterpolatedStringExpr de 3 partes, se obtuvo %#v", prog.Statements[0])
+	}
+	if _, ok := expr.Parts[1].(*ast.BinaryExpr); !ok {
+		t.Errorf("parte 1 = %T, se esperaba *ast.BinaryExpr", expr.Parts[1])
+	}
+
+	_, errs = parseSource(t, `print "a {x y} b"`)
+	if len(errs) != 1 || errs[0].Message != "Se esperaba '}' al cerrar la interpolación, se encontró 'y'" {
+		t.Errorf("errores = %v", errs)
+	}
+}
+
+func TestParseFunctionDocComment(t *testing.T) {
+	src := "/// Suma dos números.\n///\n/// Devuelve a + b.\nfunction suma(a, b)\n  return a + b\nend function\n// sin doc\nfunction resta(a, b)\n  return a - b\nend function"
+	prog, errs := parseSource(t, src)
+	if len(errs) > 0 {
+		t.Fatalf("errores inesperados: %v", errs)
+	}
+	want := []string{"Suma dos números.\n\nDevuelve a + b.", ""}
+	for i, doc := range want {
+		fn := prog.Statements[i].(*ast.FunctionStmt)
+		if fn.Doc != doc {
+			t.Errorf("%s: Doc = %q, se esperaba %q", fn.Name, fn.Doc, doc)
+		}
+	}
+}
+
+func TestParseStatementTerminators(t *testing.T) {
+	prog, errs := parseSource(t, "x = 1\n-2\ny = 1 +\n  2; print y;;\n")
+	if len(errs) > 0 {
+		t.Fatalf("errores inesperados: %v", errs)
+	}
+	if len(prog.Statements) != 4 {
+		t.Fatalf("se esperaban 4 sentencias, se obtuvieron %d", len(prog.Statements))
+	}
+	if _, ok := prog.Statements[1].(*ast.ExpressionStmt); !ok {
+		t.Errorf("sentencia 1 = %T, se esperaba *ast.ExpressionStmt", prog.Statements[1])
+	}
+	if assign := prog.Statements[2].(*ast.AssignmentStmt); assign.Value.(*ast.BinaryExpr).Operator != "+" {
+		t.Errorf("se esperaba 'y = 1 + 2' continuado en la línea siguiente")
+	}
+
+	_, errs = parseSource(t, "x = 1 2\nprint x")
+	if len(errs) != 1 || errs[0].Message != "Se esperaba fin de línea después de la sentencia, se encontró '2'" {
+		t.Errorf("errores = %v", errs)
+	}
+}
+
+func TestParseBareReturn(t *testing.T) {
+	prog, errs := parseSource(t, "function f()\n  return\nend function")
+	if len(errs) > 0 {
+		t.Fatalf("errores inesperados: %v", errs)
+	}
+	ret := prog.Statements[0].(*ast.FunctionStmt).Body[0].(*ast.ReturnStmt)
+	if ret.Value != nil {
+		t.Errorf("Value = %#v, se esperaba nil", ret.Value)
+	}
+}
+
+func TestParseSingleLineIf(t *testing.T) {
+	prog, errs := parseSource(t, "if x > 0 then print \"pos\" else print \"neg\"\nif x > 0 then\n  print \"pos\"\nelse\n  print \"neg\"\nend if")
+	if len(errs) > 0 {
+		t.Fatalf("errores inesperados: %v", errs)
+	}
+	oneLine := prog.Statements[0].(*ast.IfStmt)
+	block := prog.Statements[1].(*ast.IfStmt)
+	for _, stmt := range []*ast.IfStmt{oneLine, block} {
+		if len(stmt.ThenBlock) != 1 || len(stmt.ElseBlock) != 1 || stmt.ElseIfConds != nil {
+			t.Errorf("if en %d:%d con forma inesperada: %+v", stmt.Span.Start.Line, stmt.Span.Start.Column, stmt)
+		}
+	}
+	if oneLine.Span.End.Line != 1 {
+		t.Errorf("el if de una línea termina en la línea %d", oneLine.Span.End.Line)
+	}
+
+	prog, errs = parseSource(t, "if a then x = 1 else if b then x = 2 else x = 3")
+	if len(errs) > 0 {
+		t.Fatalf("errores inesperados: %v", errs)
+	}
+	if _, ok := prog.Statements[0].(*ast.IfStmt).ElseBlock[0].(*ast.IfStmt); !ok {
+		t.Errorf("se esperaba un if anidado en la rama else")
+	}
+}
+
+func TestParseForIn(t *testing.T) {
+	prog, errs := parseSource(t, "for item in lista[1:]\n  print item\nend for\nfor i = 1 range 3\nend for")
+	if len(errs) > 0 {
+		t.Fatalf("errores inesperados: %v", errs)
+	}
+	forIn, ok := prog.Statements[0].(*ast.ForInStmt)
+	if !ok {
+		t.Fatalf("se esperaba *ast.ForInStmt, se obtuvo %T", prog.Statements[0])
+	}
+	if forIn.VarName != "item" || len(forIn.Body) != 1 {
+		t.Errorf("ForInStmt inesperado: %+v", forIn)
+	}
+	if _, ok := forIn.Iterable.(*ast.SliceExpr); !ok {
+		t.Errorf("Iterable = %T, se esperaba *ast.SliceExpr", forIn.Iterable)
+	}
+	if _, ok := prog.Statements[1].(*ast.ForStmt); !ok {
+		t.Errorf("se esperaba *ast.ForStmt, se obtuvo %T", prog.Statements[1])
+	}
+}
+
+func TestParseForStep(t *testing.T) {
+	prog, errs := parseSource(t, "for i = 10 to 0 step -2\nend for\nfor i = 0 to 1\nend for")
+	if len(errs) > 0 {
+		t.Fatalf("errores inesperados: %v", errs)
+	}
+	withStep := prog.Statements[0].(*ast.ForStmt)
+	if unary, ok := withStep.StepExpr.(*ast.UnaryExpr); !ok || unary.Operator != "-" {
+		t.Errorf("StepExpr = %#v, se esperaba '-2'", withStep.StepExpr)
+	}
+	if prog.Statements[1].(*ast.ForStmt).StepExpr != nil {
+		t.Errorf("se esperaba StepExpr nil sin cláusula step")
+	}
+}
+
+func TestCheckJumpContext(t *testing.T) {
+	src := "break\nfunction f()\n  continue\nend function\nwhile true\n  g = function()\n    break\n  end function\n  break nada\n  a: while true\n    a: for x in [1]\n    end for\n  end while\nend while\nreturn 1"
+	_, errs := parseSource(t, src)
+	want := []struct {
+		line int
+		msg  string
+	}{
+		{1, "'break' fuera de un ciclo"},
+		{3, "'continue' fuera de un ciclo"},
+		{7, "'break' fuera de un ciclo"},
+		{9, "'break nada': no hay un ciclo con la etiqueta 'nada' que lo contenga"},
+		{11, "La etiqueta 'a' ya está en uso por un ciclo exterior"},
+		{15, "'return' fuera de una función"},
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("se esperaban %d errores, se obtuvieron %d: %v", len(want), len(errs), errs)
+	}
+	for i, w := range want {
+		if errs[i].Line != w.line || errs[i].Message != w.msg {
+			t.Errorf("error %d = %v, se esperaba %q en la línea %d", i, errs[i], w.msg, w.line)
+		}
+	}
+}
+
+func TestParseLabeledLoops(t *testing.T) {
+	prog, errs := parseSource(t, "outer: for i = 1 to 3\n  inner:\n  while true\n    break outer\n  end while\nend for")
+	if len(errs) > 0 {
+		t.Fatalf("errores inesperados: %v", errs)
+	}
+	outer := prog.Statements[0].(*ast.ForStmt)
+	if outer.Label != "outer" || outer.Span.Start.Column != 1 {
+		t.Errorf("ForStmt con etiqueta %q en la columna %d", outer.Label, outer.Span.Start.Column)
+	}
+	inner := outer.Body[0].(*ast.WhileStmt)
+	if inner.Label != "inner" {
+		t.Errorf("WhileStmt con etiqueta %q, se esperaba 'inner'", inner.Label)
+	}
+	if brk := inner.Body[0].(*ast.BreakStmt); brk.Label != "outer" {
+		t.Errorf("BreakStmt con etiqueta %q, se esperaba 'outer'", brk.Label)
+	}
+
+	_, errs = parseSource(t, "x: print 1")
+	if len(errs) != 1 || errs[0].Message != "Solo un ciclo puede llevar la etiqueta 'x', se encontró 'print'" {
+		t.Errorf("errores = %v", errs)
+	}
+}
+
+func TestParseUnclosedBracketReportsOnce(t *testing.T) {
+	for _, src := range []string{
+		"f(1\nprint 2\nprint 3\nif 1\n print 4\nend if",
+		"x = (1 +\nprint 2\nprint 3",
+		"m = {\"a\": 1\nwhile false\nend while\nprint m",
+	} {
+		prog, errs := parseSource(t, src)
+		if len(errs) != 1 {
+			t.Errorf("%q: se esperaba 1 error, se obtuvieron %d: %v", src, len(errs), errs)
+			continue
+		}
+		if n := len(prog.Statements); n < 2 {
+			t.Errorf("%q: solo se recuperaron %d sentencias", src, n)
+		}
+	}
+}
+
+func TestParseStrayEndReportedOnce(t *testing.T) {
+	_, errs := parseSource(t, "a = 1 + 2 end\nprint a")
+	if len(errs) != 1 || errs[0].Column != 11 {
+		t.Fatalf("se esperaba un único error en la columna 11, se obtuvo %v", errs)
+	}
+	if errs[0].Message != "Se esperaba fin de línea después de la sentencia, se encontró 'end'" {
+		t.Errorf("mensaje = %q", errs[0].Message)
+	}
+}